@@ -0,0 +1,186 @@
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+var batchType = reflect.TypeOf([][]interface{}(nil))
+
+// batchDistribution collects the events of a subscriber and hands them over in
+// batches of up to size events, or the events collected within window of the first
+// one of a batch. Batches are delivered one at a time in publish order.
+type batchDistribution struct {
+	topic   string
+	fn      reflect.Value
+	inv     *invoker
+	execute func(task func())
+	size    int
+	window  time.Duration
+
+	lock    sync.Mutex
+	batch   []*event
+	timer   *time.Timer
+	ready   [][]*event
+	running bool
+}
+
+func newBatchDistribution(topic string, fn reflect.Value, inv *invoker, execute func(task func()), size int, window time.Duration) *batchDistribution {
+	return &batchDistribution{
+		topic:   topic,
+		fn:      fn,
+		inv:     inv,
+		execute: execute,
+		size:    size,
+		window:  window,
+	}
+}
+
+func (d *batchDistribution) Register(ctx context.Context) {
+	return
+}
+
+func (d *batchDistribution) Key() any {
+	return d.fn.Pointer()
+}
+
+func (d *batchDistribution) Dist(data any) error {
+	ev := data.(*event)
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.batch = append(d.batch, ev)
+	if d.size > 0 && len(d.batch) >= d.size {
+		d.cut()
+		return nil
+	}
+	if len(d.batch) == 1 && d.window > 0 {
+		if d.timer == nil {
+			d.timer = time.AfterFunc(d.window, d.flush)
+		} else {
+			d.timer.Reset(d.window)
+		}
+	}
+	return nil
+}
+
+// cut closes the current batch and schedules its delivery, the lock has to be held.
+func (d *batchDistribution) cut() {
+	if len(d.batch) == 0 {
+		return
+	}
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	d.ready = append(d.ready, d.batch)
+	d.batch = nil
+	if !d.running {
+		d.running = true
+		d.execute(d.drain)
+	}
+}
+
+// flush delivers the events collected so far.
+func (d *batchDistribution) flush() {
+	d.lock.Lock()
+	d.cut()
+	d.lock.Unlock()
+}
+
+func (d *batchDistribution) drain() {
+	for {
+		d.lock.Lock()
+		if len(d.ready) == 0 {
+			d.running = false
+			d.lock.Unlock()
+			return
+		}
+		batch := d.ready[0]
+		d.ready[0] = nil
+		d.ready = d.ready[1:]
+		d.lock.Unlock()
+		d.deliver(batch)
+	}
+}
+
+func (d *batchDistribution) deliver(batch []*event) {
+	args := make([][]interface{}, 0, len(batch))
+	for _, ev := range batch {
+		args = append(args, ev.args)
+	}
+	ev := newEvent(context.Background(), d.topic, []interface{}{args})
+	ev.batch = batch
+	ev.at = batch[0].at
+	d.inv.invokeRetry(ev.ctx, ev, funcHandler(d.fn))
+}
+
+// Close delivers the remaining events before returning.
+func (d *batchDistribution) Close() error {
+	d.lock.Lock()
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	batches := append(d.ready, d.batch)
+	d.ready, d.batch = nil, nil
+	d.lock.Unlock()
+	for _, batch := range batches {
+		if len(batch) > 0 {
+			d.deliver(batch)
+		}
+	}
+	return nil
+}
+
+// SubscribeBatch subscribes fn to receive the events of topic in batches of up to size
+// events, or the events published within window of the first one of a batch.
+// fn takes the argument lists of the events, func([][]interface{}), optionally after
+// a context.Context. Pending events are delivered when the bus is drained or closed.
+func (bus *EventBus) SubscribeBatch(topic string, fn interface{}, size int, window time.Duration, opt ...SubscribeOption) (Subscription, error) {
+	fnType := reflect.TypeOf(fn)
+	if fnType == nil || fnType.Kind() != reflect.Func {
+		return nil, fmt.Errorf("%v is not of type reflect.Func", fnType)
+	}
+	if sig := newSignature(fnType); len(sig.in) != 1 || sig.in[0] != batchType {
+		return nil, fmt.Errorf("%v does not take a [][]interface{} batch", fnType)
+	}
+	if size <= 0 && window <= 0 {
+		return nil, fmt.Errorf("batch size or window must be positive")
+	}
+	if err := validatePattern(topic); err != nil {
+		return nil, err
+	}
+	if err := bus.acquire(); err != nil {
+		return nil, err
+	}
+	defer bus.release()
+
+	opts := newSubscribeOptions(opt)
+	handler := reflect.ValueOf(fn)
+	sub := &subscription{
+		Distribution: newBatchDistribution(topic, handler, bus.newInvoker(topic, funcName(handler), opts), bus.execute, size, window),
+		topic:        topic,
+		key:          subscriptionID(bus.nextID.Add(1)),
+		handler:      handler.Pointer(),
+		filter:       opts.filter(),
+		priority:     opts.priority,
+		inactive:     opts.inactive,
+		bus:          bus,
+	}
+	// the batch handler does not tell the signature of the topic
+	if err := bus.subscribe(sub, nil); err != nil {
+		return nil, err
+	}
+	bus.deliverRetained(sub)
+	return sub, nil
+}
+
+// flushBatches hands the pending batches over for delivery.
+func (bus *EventBus) flushBatches() {
+	for _, sub := range bus.subs.all() {
+		if d, ok := sub.Distribution.(*batchDistribution); ok {
+			d.flush()
+		}
+	}
+}
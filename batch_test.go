@@ -0,0 +1,130 @@
+package eventbus
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type batchRecorder struct {
+	lock    sync.Mutex
+	batches [][]int
+}
+
+func (r *batchRecorder) record(batch [][]interface{}) {
+	ints := make([]int, 0, len(batch))
+	for _, args := range batch {
+		ints = append(ints, args[0].(int))
+	}
+	r.lock.Lock()
+	r.batches = append(r.batches, ints)
+	r.lock.Unlock()
+}
+
+func (r *batchRecorder) expect(t *testing.T, expected ...[]int) {
+	t.Helper()
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if len(expected) == 0 && len(r.batches) == 0 {
+		return
+	}
+	if !reflect.DeepEqual(r.batches, expected) {
+		t.Fatalf("expected %v, got %v", expected, r.batches)
+	}
+}
+
+func TestSubscribeBatchSize(t *testing.T) {
+	e := New()
+	topic := "metrics"
+	r := &batchRecorder{}
+	if _, err := e.SubscribeBatch(topic, r.record, 3, 0); err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 7; i++ {
+		e.Publish(topic, i)
+	}
+	time.Sleep(50 * time.Millisecond)
+	r.expect(t, []int{1, 2, 3}, []int{4, 5, 6})
+	if err := e.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	r.expect(t, []int{1, 2, 3}, []int{4, 5, 6}, []int{7})
+}
+
+func TestSubscribeBatchWindow(t *testing.T) {
+	e := New()
+	topic := "metrics"
+	r := &batchRecorder{}
+	e.SubscribeBatch(topic, r.record, 100, 50*time.Millisecond)
+	e.Publish(topic, 1)
+	e.Publish(topic, 2)
+	r.expect(t)
+	time.Sleep(150 * time.Millisecond)
+	r.expect(t, []int{1, 2})
+	e.Publish(topic, 3)
+	time.Sleep(150 * time.Millisecond)
+	r.expect(t, []int{1, 2}, []int{3})
+}
+
+func TestSubscribeBatchClose(t *testing.T) {
+	e := New()
+	topic := "metrics"
+	r := &batchRecorder{}
+	e.SubscribeBatch(topic, r.record, 100, time.Hour)
+	e.Publish(topic, 1)
+	e.Publish(topic, 2)
+	if err := e.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	r.expect(t, []int{1, 2})
+}
+
+func TestSubscribeBatchOrder(t *testing.T) {
+	e := New()
+	topic := "metrics"
+	var lock sync.Mutex
+	var got []int
+	e.SubscribeBatch(topic, func(ctx context.Context, batch [][]interface{}) {
+		if ctx == nil {
+			t.Error("expected a context")
+		}
+		lock.Lock()
+		for _, args := range batch {
+			got = append(got, args[0].(int))
+		}
+		lock.Unlock()
+	}, 7, 0)
+	n := 1000
+	for i := 0; i < n; i++ {
+		e.Publish(topic, i)
+	}
+	e.Drain(context.Background())
+	lock.Lock()
+	defer lock.Unlock()
+	if len(got) != n {
+		t.Fatalf("expected %d events, got %d", n, len(got))
+	}
+	for i, v := range got {
+		if v != i {
+			t.Fatalf("expected %d at %d, got %d", i, i, v)
+		}
+	}
+}
+
+func TestSubscribeBatchInvalid(t *testing.T) {
+	e := New()
+	if _, err := e.SubscribeBatch("metrics", func(i int) {}, 10, 0); err == nil {
+		t.Fatal("expected an error for a handler not taking a batch")
+	}
+	if _, err := e.SubscribeBatch("metrics", func(batch [][]interface{}) {}, 0, 0); err == nil {
+		t.Fatal("expected an error without size and window")
+	}
+	// batch handlers do not bind the topic signature
+	r := &batchRecorder{}
+	e.SubscribeBatch("metrics", r.record, 1, 0)
+	if _, err := e.SubscribeSync("metrics", func(i int) {}); err != nil {
+		t.Fatal(err)
+	}
+}
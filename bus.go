@@ -2,119 +2,403 @@ package eventbus
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/danielhookx/fission"
 )
 
 type BusSubscriber interface {
-	Subscribe(topic string, fn interface{}) error
-	SubscribeSync(topic string, fn interface{}) error
+	Subscribe(topic string, fn interface{}, opt ...SubscribeOption) (Subscription, error)
+	SubscribeSync(topic string, fn interface{}, opt ...SubscribeOption) (Subscription, error)
+	SubscribeOrdered(topic string, fn interface{}, opt ...SubscribeOption) (Subscription, error)
+	SubscribeOnce(topic string, fn interface{}, opt ...SubscribeOption) (Subscription, error)
+	SubscribeOnceSync(topic string, fn interface{}, opt ...SubscribeOption) (Subscription, error)
+	SubscribeWithReplay(topic string, fn interface{}, replay Replay, opt ...SubscribeOption) (Subscription, error)
+	SubscribeBatch(topic string, fn interface{}, size int, window time.Duration, opt ...SubscribeOption) (Subscription, error)
+	WaitFor(ctx context.Context, topic string) ([]interface{}, error)
 	Unsubscribe(topic string, key any) error
-	SubscribeWith(topic string, key any, distHandler fission.CreateDistributionHandleFunc) error
+	SubscribeWith(topic string, key any, distHandler fission.CreateDistributionHandleFunc, opt ...SubscribeOption) (Subscription, error)
 }
 
 type BusPublisher interface {
 	Publish(topic string, args ...interface{})
+	PublishE(topic string, args ...interface{}) error
+	PublishContext(ctx context.Context, topic string, args ...interface{}) error
+	PublishAfter(d time.Duration, topic string, args ...interface{}) (Scheduled, error)
+	PublishAt(t time.Time, topic string, args ...interface{}) (Scheduled, error)
+}
+
+type BusInspector interface {
+	QueueStats(topic string) []QueueStats
+	Topics() []string
+	Subscribers(topic string) []SubscriberInfo
+	HasSubscribers(topic string) bool
+}
+
+type BusController interface {
+	Drain(ctx context.Context) error
+	Close(ctx context.Context) error
 }
 
 type Eventbus interface {
 	BusSubscriber
 	BusPublisher
+	BusRequester
+	BusRetainer
+	BusInspector
+	BusController
 }
 
 type EventBus struct {
-	cm *fission.CenterManager
-	dm *fission.DistributorManager
+	topics      *topicTree
+	dm          *fission.DistributorManager
+	sigs        *signatureRegistry
+	subs        *subscriptionRegistry
+	nextID      atomic.Uint64
+	onError     ErrorHandler
+	middlewares *middlewares
+	retained    *retainStore
+	replay      *replayBuffers
+	deadLetters *deadLetters
+	metrics     MetricsCollector
+	tracer      Tracer
+	execute     func(task func())
+
+	scheduler      *scheduler
+	schedulePolicy SchedulePolicy
+
+	closeLock sync.RWMutex
+	closed    bool
+	// shutdown is closed by Drain, it stops the backoff of retried deliveries
+	shutdown chan struct{}
+	inflight inflight
 }
 
 func New(opt ...EventbusOption) Eventbus {
-	opts := eventbusOptions{}
+	opts := eventbusOptions{
+		errorHandler: defaultErrorHandler,
+		tracer:       noopTracer{},
+	}
 	for _, o := range opt {
 		o.apply(&opts)
 	}
-	var bus Eventbus
-	bus = &EventBus{
-		cm: fission.NewCenterManager(),
-		dm: fission.NewDistributorManager(),
+	sigs := newSignatureRegistry()
+	b := &EventBus{
+		topics:  newTopicTree(),
+		dm:      fission.NewDistributorManager(),
+		sigs:    sigs,
+		subs:    newSubscriptionRegistry(sigs),
+		onError: opts.errorHandler,
+		middlewares: &middlewares{
+			global: opts.middleware,
+			topics: opts.topicMiddleware,
+		},
+		retained:    newRetainStore(opts.retain),
+		replay:      newReplayBuffers(opts.replay),
+		deadLetters: opts.deadLetters,
+		metrics:     opts.metrics,
+		tracer:      opts.tracer,
+
+		schedulePolicy: opts.schedulePolicy,
+		shutdown:       make(chan struct{}),
+	}
+	b.scheduler = newScheduler(b.publishScheduled)
+	if opts.pool != nil {
+		b.execute = b.track(opts.pool.Submit)
+	} else {
+		b.execute = b.track(goExecute)
 	}
+	var bus Eventbus = b
 	for _, proxyCreator := range opts.proxyCreators {
 		bus = proxyCreator(bus)
 	}
 	return bus
 }
 
-func (bus *EventBus) Subscribe(topic string, fn interface{}) error {
-	fnType := reflect.TypeOf(fn)
-	if !(fnType.Kind() == reflect.Func) {
-		return fmt.Errorf("%s is not of type reflect.Func", fnType.Kind())
-	}
+func (bus *EventBus) Subscribe(topic string, fn interface{}, opt ...SubscribeOption) (Subscription, error) {
+	opts := newSubscribeOptions(opt)
+	return bus.subscribeFunc(topic, fn, opts, func(handler reflect.Value, inv *invoker) fission.Distribution {
+		if opts.queueSize > 0 {
+			return newQueuedDistribution(topic, handler, inv, bus.execute, false, opts)
+		}
+		return newAsyncDistribution(topic, handler, inv, bus.execute)
+	})
+}
 
-	handler := reflect.ValueOf(fn)
-	key := handler.Pointer()
+func (bus *EventBus) SubscribeSync(topic string, fn interface{}, opt ...SubscribeOption) (Subscription, error) {
+	return bus.subscribeFunc(topic, fn, newSubscribeOptions(opt), func(handler reflect.Value, inv *invoker) fission.Distribution {
+		return newSyncDistribution(topic, handler, inv)
+	})
+}
 
-	r := bus.cm.PutCenter(topic)
-	p := bus.dm.PutDistributor(key, createEventBusRepeatDist)
-	p.Register(toDistCtx(newAsyncDistribution(handler)))
-	r.AddDistributor(p)
-	return nil
+// SubscribeOrdered subscribes fn asynchronously while guaranteeing that it
+// observes events in publish order.
+func (bus *EventBus) SubscribeOrdered(topic string, fn interface{}, opt ...SubscribeOption) (Subscription, error) {
+	opts := newSubscribeOptions(opt)
+	return bus.subscribeFunc(topic, fn, opts, func(handler reflect.Value, inv *invoker) fission.Distribution {
+		return newQueuedDistribution(topic, handler, inv, bus.execute, true, opts)
+	})
+}
+
+// SubscribeOnce subscribes fn asynchronously for the next event only.
+func (bus *EventBus) SubscribeOnce(topic string, fn interface{}, opt ...SubscribeOption) (Subscription, error) {
+	opt = append(opt, withOnce())
+	return bus.Subscribe(topic, fn, opt...)
+}
+
+// SubscribeOnceSync subscribes fn synchronously for the next event only.
+func (bus *EventBus) SubscribeOnceSync(topic string, fn interface{}, opt ...SubscribeOption) (Subscription, error) {
+	opt = append(opt, withOnce())
+	return bus.SubscribeSync(topic, fn, opt...)
+}
+
+// WaitFor blocks until the next event is published to topic and returns its arguments.
+func (bus *EventBus) WaitFor(ctx context.Context, topic string) ([]interface{}, error) {
+	if err := validatePattern(topic); err != nil {
+		return nil, err
+	}
+	d := newWaitDistribution()
+	sub := &subscription{
+		Distribution: d,
+		topic:        topic,
+		key:          subscriptionID(bus.nextID.Add(1)),
+		once:         true,
+		bus:          bus,
+	}
+	if err := bus.acquire(); err != nil {
+		return nil, err
+	}
+	err := bus.subscribe(sub, nil)
+	bus.release()
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case args := <-d.ch:
+		return args, nil
+	case <-d.closed:
+		return nil, ErrClosed
+	case <-ctx.Done():
+		sub.Unsubscribe()
+		return nil, ctx.Err()
+	}
 }
 
-func (bus *EventBus) SubscribeSync(topic string, fn interface{}) error {
+func (bus *EventBus) subscribeFunc(topic string, fn interface{}, opts *subscribeOptions, create func(handler reflect.Value, inv *invoker) fission.Distribution) (Subscription, error) {
 	fnType := reflect.TypeOf(fn)
-	if !(fnType.Kind() == reflect.Func) {
-		return fmt.Errorf("%s is not of type reflect.Func", fnType.Kind())
+	if fnType == nil || fnType.Kind() != reflect.Func {
+		return nil, fmt.Errorf("%v is not of type reflect.Func", fnType)
+	}
+	if err := validatePattern(topic); err != nil {
+		return nil, err
 	}
+	if err := bus.acquire(); err != nil {
+		return nil, err
+	}
+	defer bus.release()
 
 	handler := reflect.ValueOf(fn)
-	key := handler.Pointer()
-
-	r := bus.cm.PutCenter(topic)
-	p := bus.dm.PutDistributor(key, createEventBusRepeatDist)
-	p.Register(toDistCtx(newSyncDistribution(handler)))
-	r.AddDistributor(p)
-	return nil
+	sub := &subscription{
+		Distribution: create(handler, bus.newInvoker(topic, funcName(handler), opts)),
+		topic:        topic,
+		key:          subscriptionID(bus.nextID.Add(1)),
+		handler:      handler.Pointer(),
+		responder:    fnType.NumOut() > 0,
+		filter:       opts.filter(),
+		once:         opts.once,
+		priority:     opts.priority,
+		inactive:     opts.inactive,
+		bus:          bus,
+	}
+	if opts.replay != nil {
+		sub.gate = &replayGate{}
+	}
+	bus.limit(sub, opts)
+	if err := bus.subscribe(sub, fnType); err != nil {
+		return nil, err
+	}
+	if opts.replay != nil {
+		// a replaying subscription receives the buffered events instead of the retained ones
+		bus.replayTo(sub, *opts.replay)
+	} else {
+		bus.deliverRetained(sub)
+	}
+	return sub, nil
 }
 
-func (bus *EventBus) SubscribeWith(topic string, key any, distHandler fission.CreateDistributionHandleFunc) error {
+// SubscribeWith registers the distribution created by distHandler for key.
+// A key is only subscribed once per topic, later calls return the existing subscription.
+func (bus *EventBus) SubscribeWith(topic string, key any, distHandler fission.CreateDistributionHandleFunc, opt ...SubscribeOption) (Subscription, error) {
+	opts := newSubscribeOptions(opt)
 	if key == nil {
-		return fmt.Errorf("key is nil")
+		return nil, fmt.Errorf("key is nil")
+	}
+	if err := validatePattern(topic); err != nil {
+		return nil, err
+	}
+	if err := bus.acquire(); err != nil {
+		return nil, err
+	}
+	defer bus.release()
+	if subs := bus.subs.find(topic, func(s *subscription) bool { return s.key == key }); len(subs) > 0 {
+		return subs[0], nil
 	}
 
-	r := bus.cm.PutCenter(topic)
 	p := bus.dm.PutDistributor(key, distHandler)
 	p.Register(nil)
-	r.AddDistributor(p)
+	_, responder := p.(requestDistribution)
+	sub := &subscription{
+		Distribution: newSafeDistribution(p, bus.newInvoker(topic, fmt.Sprint(key), opts)),
+		topic:        topic,
+		key:          key,
+		responder:    responder,
+		filter:       opts.filter(),
+		priority:     opts.priority,
+		inactive:     opts.inactive,
+		bus:          bus,
+	}
+	bus.limit(sub, opts)
+	if err := bus.subscribe(sub, nil); err != nil {
+		return nil, err
+	}
+	bus.deliverRetained(sub)
+	return sub, nil
+}
+
+func (bus *EventBus) subscribe(sub *subscription, fnType reflect.Type) error {
+	sub.active.Store(true)
+	if err := bus.subs.add(sub, fnType); err != nil {
+		return err
+	}
+	bus.topics.put(sub.topic).add(sub)
 	return nil
 }
 
+func (bus *EventBus) unsubscribe(sub *subscription) {
+	if !sub.active.CompareAndSwap(true, false) {
+		return
+	}
+	bus.detach(sub)
+	sub.deactivated()
+}
+
+func (bus *EventBus) detach(sub *subscription) {
+	if c := bus.topics.get(sub.topic); c != nil {
+		c.remove(sub.key)
+	}
+	bus.subs.remove(sub)
+}
+
+// Unsubscribe removes the subscriptions of topic made with the handler or the SubscribeWith key.
+// All subscriptions sharing the handler function are removed, use Subscription.Unsubscribe
+// to remove a single one.
 func (bus *EventBus) Unsubscribe(topic string, key any) error {
-	fnType := reflect.TypeOf(key)
-	if fnType.Kind() == reflect.Func {
-		r := bus.cm.PutCenter(topic)
-		r.DelDistributor(reflect.ValueOf(key).Pointer())
-		return nil
-	}
-	r := bus.cm.PutCenter(topic)
-	r.DelDistributor(key)
+	var match func(s *subscription) bool
+	if fnType := reflect.TypeOf(key); fnType != nil && fnType.Kind() == reflect.Func {
+		ptr := reflect.ValueOf(key).Pointer()
+		match = func(s *subscription) bool {
+			return s.handler == ptr
+		}
+	} else {
+		match = func(s *subscription) bool {
+			return s.handler == 0 && s.key == key
+		}
+	}
+	for _, sub := range bus.subs.find(topic, match) {
+		bus.unsubscribe(sub)
+	}
 	return nil
 }
 
 func (bus *EventBus) Publish(topic string, args ...interface{}) {
-	r := bus.cm.PutCenter(topic)
-	r.Fission(args)
-	return
+	if err := bus.PublishE(topic, args...); err != nil {
+		bus.onError(err)
+	}
+}
+
+func (bus *EventBus) PublishE(topic string, args ...interface{}) error {
+	return bus.PublishContext(context.Background(), topic, args...)
+}
+
+// PublishContext publishes args to topic, handlers taking a context.Context as first
+// parameter receive ctx. Asynchronous handlers receive its values but not its cancellation.
+func (bus *EventBus) PublishContext(ctx context.Context, topic string, args ...interface{}) error {
+	return bus.publish(newEvent(ctx, topic, args))
+}
+
+func (bus *EventBus) publish(ev *event) error {
+	if err := validateTopic(ev.topic); err != nil {
+		return err
+	}
+	if err := bus.acquire(); err != nil {
+		return err
+	}
+	defer bus.release()
+	ctx, span := bus.tracer.StartPublish(ev.ctx, ev.topic)
+	ev.ctx = ctx
+	if bus.metrics != nil {
+		ev.at = time.Now()
+	}
+	err := bus.dispatch(ev)
+	if bus.metrics != nil {
+		bus.metrics.Published(ev.topic, err)
+	}
+	span.End(err)
+	return err
+}
+
+// dispatch delivers ev to the subscribers of its topic, the bus has to be acquired.
+func (bus *EventBus) dispatch(ev *event) error {
+	retain := ev.reply == nil && (isRetained(ev.ctx) || bus.retained.retains(ev.topic))
+	if retain {
+		ev.ctx = withRetained(ev.ctx)
+	}
+	if ev.reply == nil {
+		bus.replay.record(ev)
+	}
+	var groups [][]*subscription
+	for _, node := range bus.topics.match(ev.topic) {
+		// a pattern whose signature does not fit is skipped, the others still receive the event
+		if err := bus.sigs.check(node.pattern, ev.args); err != nil {
+			ev.fail(err)
+			retain = false
+			continue
+		}
+		groups = append(groups, node.center.snapshot())
+	}
+	if retain {
+		bus.retained.set(ev.topic, ev.args)
+	}
+	deliver(mergeByPriority(groups), ev)
+	return ev.err()
+}
+
+func (bus *EventBus) QueueStats(topic string) []QueueStats {
+	var stats []QueueStats
+	for _, sub := range bus.subs.list(topic) {
+		if d, ok := sub.Distribution.(*queuedDistribution); ok {
+			stats = append(stats, d.stats())
+		}
+	}
+	return stats
 }
 
 type syncDistribution struct {
-	fn reflect.Value
+	topic string
+	fn    reflect.Value
+	inv   *invoker
 }
 
-func newSyncDistribution(fn reflect.Value) *syncDistribution {
+func newSyncDistribution(topic string, fn reflect.Value, inv *invoker) *syncDistribution {
 	return &syncDistribution{
-		fn: fn,
+		topic: topic,
+		fn:    fn,
+		inv:   inv,
 	}
 }
 
@@ -127,8 +411,9 @@ func (d *syncDistribution) Key() any {
 }
 
 func (d *syncDistribution) Dist(data any) error {
-	passedArguments := setFuncArgs(d.fn, data.([]interface{}))
-	d.fn.Call(passedArguments)
+	ev := data.(*event)
+	ev.expectReply()
+	ev.respond(d.inv.invoke(propagationContext{Context: ev.ctx, ev: ev}, ev, funcHandler(d.fn)))
 	return nil
 }
 
@@ -137,12 +422,18 @@ func (d *syncDistribution) Close() error {
 }
 
 type asyncDistribution struct {
-	fn reflect.Value
+	topic   string
+	fn      reflect.Value
+	inv     *invoker
+	execute func(task func())
 }
 
-func newAsyncDistribution(fn reflect.Value) *asyncDistribution {
+func newAsyncDistribution(topic string, fn reflect.Value, inv *invoker, execute func(task func())) *asyncDistribution {
 	return &asyncDistribution{
-		fn: fn,
+		topic:   topic,
+		fn:      fn,
+		inv:     inv,
+		execute: execute,
 	}
 }
 
@@ -155,10 +446,12 @@ func (d *asyncDistribution) Key() any {
 }
 
 func (d *asyncDistribution) Dist(data any) error {
-	go func() {
-		passedArguments := setFuncArgs(d.fn, data.([]interface{}))
-		d.fn.Call(passedArguments)
-	}()
+	ev := data.(*event)
+	ctx := detachContext(ev.ctx)
+	ev.expectReply()
+	d.execute(func() {
+		ev.respond(d.inv.invokeRetry(ctx, ev, funcHandler(d.fn)))
+	})
 	return nil
 }
 
@@ -166,66 +459,121 @@ func (d *asyncDistribution) Close() error {
 	return nil
 }
 
-type repeatDistribution struct {
-	key   any
-	lock  sync.RWMutex
-	dists []fission.Distribution
+// eventDistribution is implemented by internal distributions registered through
+// SubscribeWith that need the context and published topic along with the arguments.
+type eventDistribution interface {
+	distEvent(ctx context.Context, topic string, args []interface{}) error
 }
 
-func newRepeatDistribution(key any) *repeatDistribution {
-	return &repeatDistribution{
-		key:   key,
-		dists: []fission.Distribution{},
-	}
+// safeDistribution isolates a custom distribution so that its panics and
+// errors are reported instead of interrupting delivery to other subscribers.
+type safeDistribution struct {
+	fission.Distribution
+	inv *invoker
 }
 
-func (d *repeatDistribution) Register(ctx context.Context) {
-	d.lock.Lock()
-	dist := fromDistCtx(ctx)
-	d.dists = append(d.dists, dist)
-	d.lock.Unlock()
+func newSafeDistribution(dist fission.Distribution, inv *invoker) *safeDistribution {
+	return &safeDistribution{
+		Distribution: dist,
+		inv:          inv,
+	}
 }
 
-func (d *repeatDistribution) Key() any {
-	return d.key
+func (d *safeDistribution) Dist(data any) error {
+	ev := data.(*event)
+	var err error
+	d.inv.invoke(ev.ctx, ev, func(ctx context.Context, dl *Delivery) ([]interface{}, error) {
+		if rd, ok := d.Distribution.(requestDistribution); ok && ev.reply != nil {
+			err = rd.distRequest(ctx, dl.Topic, dl.Args, ev.reply)
+		} else if ed, ok := d.Distribution.(eventDistribution); ok {
+			err = ed.distEvent(ctx, dl.Topic, dl.Args)
+		} else {
+			err = d.Distribution.Dist(dl.Args)
+		}
+		return nil, err
+	})
+	// the invoker reports the errors of publishes, those of requests are reported here
+	if err != nil && ev.reply != nil {
+		d.inv.onError(&HandlerError{
+			Topic:   d.inv.pattern,
+			Handler: d.inv.subscriber,
+			Err:     err,
+		})
+	}
+	return nil
 }
 
-func (d *repeatDistribution) Dist(data any) error {
-	d.lock.RLock()
-	dists := make([]fission.Distribution, len(d.dists))
-	copy(dists, d.dists)
-	d.lock.RUnlock()
-	for _, dist := range dists {
-		dist.Dist(data)
+// event is passed to the distributions of a topic on each publish and collects
+// the errors that have to be returned to the publisher.
+type event struct {
+	ctx   context.Context
+	topic string
+	args  []interface{}
+	reply *replies
+	// seq numbers the events of topics with a replay buffer
+	seq uint64
+	// batch holds the events delivered together to a batch subscription
+	batch []*event
+	// at is the publish time of the event when the bus collects metrics
+	at      time.Time
+	stopped atomic.Bool
+	lock    sync.Mutex
+	errs    []error
+}
+
+func newEvent(ctx context.Context, topic string, args []interface{}) *event {
+	return &event{
+		ctx:   ctx,
+		topic: topic,
+		args:  args,
 	}
-	return nil
 }
 
-func (d *repeatDistribution) Close() error {
-	return nil
+func (e *event) fail(err error) {
+	e.lock.Lock()
+	e.errs = append(e.errs, err)
+	e.lock.Unlock()
+}
+
+func (e *event) err() error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return errors.Join(e.errs...)
 }
 
-func toDistCtx(dist fission.Distribution) context.Context {
-	return context.WithValue(context.Background(), "eventbus", dist)
+// expectReply has to be called before a request is handed over to a handler.
+func (e *event) expectReply() {
+	if e.reply != nil {
+		e.reply.expect()
+	}
 }
 
-func fromDistCtx(ctx context.Context) fission.Distribution {
-	dist := ctx.Value("eventbus")
-	return dist.(fission.Distribution)
+// respond sends r to the requester when e is a request.
+func (e *event) respond(r reply) {
+	if e.reply != nil {
+		e.reply.send(r)
+	}
 }
 
-func createEventBusRepeatDist(key any) fission.Distribution {
-	return newRepeatDistribution(key)
+// funcHandler calls fn with the arguments of a delivery.
+func funcHandler(fn reflect.Value) Handler {
+	return func(ctx context.Context, d *Delivery) ([]interface{}, error) {
+		r := newReply(fn, fn.Call(setFuncArgs(ctx, fn, d.Args)))
+		return r.values, r.err
+	}
 }
 
-func setFuncArgs(fn reflect.Value, args []interface{}) []reflect.Value {
-	funcType := fn.Type()
-	passedArguments := make([]reflect.Value, len(args))
+func setFuncArgs(ctx context.Context, fn reflect.Value, args []interface{}) []reflect.Value {
+	sig := newSignature(fn.Type())
+	passedArguments := make([]reflect.Value, 0, len(args)+1)
+	if sig.context {
+		passedArguments = append(passedArguments, reflect.ValueOf(&ctx).Elem())
+	}
 	for i, v := range args {
 		if v == nil {
-			passedArguments[i] = reflect.New(funcType.In(i)).Elem()
+			passedArguments = append(passedArguments, reflect.Zero(sig.paramType(i)))
 		} else {
-			passedArguments[i] = reflect.ValueOf(v)
+			passedArguments = append(passedArguments, reflect.ValueOf(v))
 		}
 	}
 	return passedArguments
@@ -0,0 +1,102 @@
+package eventbus
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// center holds the subscriptions of a topic pattern ordered by descending
+// priority, subscriptions with the same priority keep their subscribe order.
+type center struct {
+	sync.RWMutex
+	subs []*subscription
+}
+
+func newCenter() *center {
+	return &center{}
+}
+
+func (c *center) add(s *subscription) {
+	c.Lock()
+	defer c.Unlock()
+	i := sort.Search(len(c.subs), func(i int) bool {
+		return c.subs[i].priority < s.priority
+	})
+	subs := make([]*subscription, 0, len(c.subs)+1)
+	subs = append(subs, c.subs[:i]...)
+	subs = append(subs, s)
+	subs = append(subs, c.subs[i:]...)
+	c.subs = subs
+}
+
+func (c *center) remove(key any) {
+	c.Lock()
+	defer c.Unlock()
+	subs := make([]*subscription, 0, len(c.subs))
+	for _, s := range c.subs {
+		if s.key != key {
+			subs = append(subs, s)
+		}
+	}
+	c.subs = subs
+}
+
+// snapshot returns the current subscriptions, the slice must not be modified.
+func (c *center) snapshot() []*subscription {
+	c.RLock()
+	defer c.RUnlock()
+	return c.subs
+}
+
+// deliver hands ev to subs in order until a handler stops the propagation.
+func deliver(subs []*subscription, ev *event) {
+	for _, s := range subs {
+		if ev.stopped.Load() {
+			return
+		}
+		if !s.accepts(ev) {
+			continue
+		}
+		s.Dist(ev)
+	}
+}
+
+// mergeByPriority merges the ordered subscriptions of several centers into a
+// single delivery order.
+func mergeByPriority(groups [][]*subscription) []*subscription {
+	if len(groups) == 1 {
+		return groups[0]
+	}
+	var subs []*subscription
+	for _, g := range groups {
+		subs = append(subs, g...)
+	}
+	sort.SliceStable(subs, func(i, j int) bool {
+		return subs[i].priority > subs[j].priority
+	})
+	return subs
+}
+
+type propagationKey struct{}
+
+// StopPropagation prevents the event delivered with ctx from reaching subscribers
+// with a lower priority. It only has an effect in synchronous handlers.
+func StopPropagation(ctx context.Context) {
+	if ev, ok := ctx.Value(propagationKey{}).(*event); ok {
+		ev.stopped.Store(true)
+	}
+}
+
+// propagationContext lets a synchronous handler reach the event it is called for.
+type propagationContext struct {
+	context.Context
+	ev *event
+}
+
+func (c propagationContext) Value(key any) any {
+	if key == (propagationKey{}) {
+		return c.ev
+	}
+	return c.Context.Value(key)
+}
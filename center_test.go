@@ -0,0 +1,73 @@
+package eventbus
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestPriorityOrder(t *testing.T) {
+	e := New()
+	topic := "testpub1"
+	var order []string
+	e.SubscribeSync(topic, func(i int) { order = append(order, "low") }, WithPriority(-1))
+	e.SubscribeSync(topic, func(i int) { order = append(order, "default1") })
+	e.SubscribeSync(topic, func(i int) { order = append(order, "high") }, WithPriority(10))
+	e.SubscribeSync(topic, func(i int) { order = append(order, "default2") })
+	e.Publish(topic, 1)
+
+	expected := []string{"high", "default1", "default2", "low"}
+	if !reflect.DeepEqual(order, expected) {
+		t.Fatalf("expected %v, got %v", expected, order)
+	}
+}
+
+func TestPriorityAcrossWildcards(t *testing.T) {
+	e := New()
+	var order []string
+	e.SubscribeSync("orders.#", func(id string) { order = append(order, "multi") }, WithPriority(1))
+	e.SubscribeSync("orders.created", func(id string) { order = append(order, "exact") })
+	e.SubscribeSync("orders.*", func(id string) { order = append(order, "single") }, WithPriority(5))
+	e.Publish("orders.created", "1")
+
+	expected := []string{"single", "multi", "exact"}
+	if !reflect.DeepEqual(order, expected) {
+		t.Fatalf("expected %v, got %v", expected, order)
+	}
+}
+
+func TestStopPropagation(t *testing.T) {
+	e := New()
+	var order []string
+	e.SubscribeSync("orders.*", func(ctx context.Context, id string) {
+		order = append(order, "guard")
+		if id == "blocked" {
+			StopPropagation(ctx)
+		}
+	}, WithPriority(10))
+	e.SubscribeSync("orders.created", func(id string) { order = append(order, id) })
+
+	e.Publish("orders.created", "blocked")
+	e.Publish("orders.created", "1")
+
+	expected := []string{"guard", "guard", "1"}
+	if !reflect.DeepEqual(order, expected) {
+		t.Fatalf("expected %v, got %v", expected, order)
+	}
+}
+
+func TestPriorityUnsubscribe(t *testing.T) {
+	e := New()
+	topic := "testpub1"
+	var order []string
+	e.SubscribeSync(topic, func(i int) { order = append(order, "a") }, WithPriority(1))
+	sub, _ := e.SubscribeSync(topic, func(i int) { order = append(order, "b") }, WithPriority(1))
+	e.SubscribeSync(topic, func(i int) { order = append(order, "c") }, WithPriority(1))
+	sub.Unsubscribe()
+	e.Publish(topic, 1)
+
+	expected := []string{"a", "c"}
+	if !reflect.DeepEqual(order, expected) {
+		t.Fatalf("expected %v, got %v", expected, order)
+	}
+}
@@ -0,0 +1,49 @@
+package eventbus
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+// Metadata holds request-scoped values that are carried with an event across RPCProxy.
+type Metadata map[string]string
+
+type metadataKey struct{}
+
+func ContextWithMetadata(ctx context.Context, md Metadata) context.Context {
+	return context.WithValue(ctx, metadataKey{}, md)
+}
+
+func MetadataFromContext(ctx context.Context) Metadata {
+	md, _ := ctx.Value(metadataKey{}).(Metadata)
+	return md
+}
+
+// detachedContext keeps the values of its parent but not its deadline and
+// cancellation, asynchronous handlers may still run after the publisher returned.
+type detachedContext struct {
+	parent context.Context
+}
+
+func detachContext(ctx context.Context) context.Context {
+	return detachedContext{parent: ctx}
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+func (c detachedContext) Value(key any) any {
+	return c.parent.Value(key)
+}
@@ -0,0 +1,87 @@
+package eventbus
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+type traceKey struct{}
+
+func TestPublishContextSync(t *testing.T) {
+	e := New()
+	topic := "testpub1"
+	var got []string
+	e.SubscribeSync(topic, func(ctx context.Context, name string) {
+		got = append(got, ctx.Value(traceKey{}).(string)+":"+name)
+	})
+	e.SubscribeSync(topic, func(name string) {
+		got = append(got, name)
+	})
+	ctx := context.WithValue(context.Background(), traceKey{}, "trace-1")
+	if err := e.PublishContext(ctx, topic, "jack"); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0] != "trace-1:jack" || got[1] != "jack" {
+		t.Fatalf("unexpected deliveries %v", got)
+	}
+}
+
+func TestPublishContextAsyncDetached(t *testing.T) {
+	e := New()
+	topic := "testpub1"
+	wg := sync.WaitGroup{}
+	wg.Add(2)
+	check := func(ctx context.Context, name string) {
+		defer wg.Done()
+		time.Sleep(time.Millisecond * 20)
+		if ctx.Err() != nil {
+			t.Errorf("async handler context should not be cancelled, got %v", ctx.Err())
+		}
+		if ctx.Value(traceKey{}) != "trace-1" {
+			t.Errorf("expected context values to be kept")
+		}
+	}
+	e.Subscribe(topic, check)
+	e.SubscribeOrdered(topic, check)
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), traceKey{}, "trace-1"))
+	e.PublishContext(ctx, topic, "jack")
+	cancel()
+	wg.Wait()
+}
+
+func TestPublishContextBackground(t *testing.T) {
+	e := New()
+	topic := "testpub1"
+	called := false
+	e.SubscribeSync(topic, func(ctx context.Context) {
+		called = ctx != nil
+	})
+	e.Publish(topic)
+	if !called {
+		t.Fatal("expected handler to receive a context")
+	}
+}
+
+func TestContextSignature(t *testing.T) {
+	e := New()
+	topic := "testpub1"
+	e.SubscribeSync(topic, func(ctx context.Context, name string) {})
+	if _, err := e.SubscribeSync(topic, func(name string) {}); err != nil {
+		t.Fatalf("context parameter should not be part of the signature, got %v", err)
+	}
+	if err := e.PublishE(topic, context.Background(), "jack"); err == nil {
+		t.Fatal("expected mismatch error when passing the context as argument")
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	ctx := ContextWithMetadata(context.Background(), Metadata{"trace-id": "1"})
+	if MetadataFromContext(ctx)["trace-id"] != "1" {
+		t.Fatal("expected metadata from context")
+	}
+	if MetadataFromContext(context.Background()) != nil {
+		t.Fatal("expected no metadata")
+	}
+}
@@ -0,0 +1,75 @@
+package eventbus
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// DeadLetterSuffix is appended to the topic of a failed delivery to name its
+// dead-letter topic, unless a single one is set with WithDeadLetterTopic.
+const DeadLetterSuffix = ".dlq"
+
+// DeadLetter is published to the dead-letter topic for every failed delivery.
+type DeadLetter struct {
+	// Topic and Args are the failed event, publishing them again replays it.
+	Topic string
+	Args  []interface{}
+	// Pattern is the topic pattern of the subscriber.
+	Pattern string
+	// Subscriber is the handler function name, or the key given to SubscribeWith.
+	Subscriber string
+	// Error is the message of the error, or of the panic, the delivery failed with.
+	Error    string
+	Attempts int
+	Time     time.Time
+}
+
+type deadLetters struct {
+	// topic is the dead-letter topic of every topic, the suffixed topic is used when empty
+	topic string
+}
+
+// topicOf returns the dead-letter topic of topic. Failed deliveries of dead letters
+// are not dead-lettered again.
+func (d *deadLetters) topicOf(topic string) (string, bool) {
+	if d.topic != "" {
+		return d.topic, topic != d.topic
+	}
+	if strings.HasSuffix(topic, DeadLetterSuffix) {
+		return "", false
+	}
+	return topic + DeadLetterSuffix, true
+}
+
+// deadLetter publishes the failed delivery of ev to the subscriber described by i,
+// every event of a failed batch is dead-lettered on its own.
+func (bus *EventBus) deadLetter(i *invoker, ev *event, err error, attempts int) {
+	events := []*event{ev}
+	if ev.batch != nil {
+		events = ev.batch
+	}
+	for _, e := range events {
+		topic, ok := bus.deadLetters.topicOf(e.topic)
+		if !ok {
+			continue
+		}
+		dl := DeadLetter{
+			Topic:      e.topic,
+			Args:       e.args,
+			Pattern:    i.pattern,
+			Subscriber: i.subscriber,
+			Error:      err.Error(),
+			Attempts:   attempts,
+			Time:       time.Now(),
+		}
+		// the failed delivery holds the bus, so that its dead letter is published while draining as well
+		err := validateTopic(topic)
+		if err == nil {
+			err = bus.dispatch(newEvent(context.Background(), topic, []interface{}{dl}))
+		}
+		if err != nil {
+			bus.onError(err)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type deadLetterRecorder struct {
+	lock sync.Mutex
+	got  []DeadLetter
+}
+
+func (r *deadLetterRecorder) record(dl DeadLetter) {
+	r.lock.Lock()
+	r.got = append(r.got, dl)
+	r.lock.Unlock()
+}
+
+func (r *deadLetterRecorder) letters() []DeadLetter {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]DeadLetter(nil), r.got...)
+}
+
+func TestDeadLetter(t *testing.T) {
+	e := New(WithDeadLetter(), WithErrorHandler(nil))
+	r := &deadLetterRecorder{}
+	e.SubscribeSync("orders.created.dlq", r.record)
+	e.SubscribeSync("orders.created", func(id string) error {
+		return errors.New("out of stock")
+	})
+	e.SubscribeSync("orders.created", func(id string) {
+		panic("boom")
+	})
+	e.SubscribeSync("orders.created", func(id string) {})
+	e.Publish("orders.created", "o-1")
+
+	got := r.letters()
+	if len(got) != 2 {
+		t.Fatalf("expected 2 dead letters, got %v", got)
+	}
+	for i, msg := range []string{"out of stock", "boom"} {
+		dl := got[i]
+		if dl.Topic != "orders.created" || !reflect.DeepEqual(dl.Args, []interface{}{"o-1"}) {
+			t.Fatalf("unexpected event %q %v", dl.Topic, dl.Args)
+		}
+		if dl.Pattern != "orders.created" || dl.Subscriber == "" || dl.Attempts != 1 || dl.Time.IsZero() {
+			t.Fatalf("unexpected dead letter %+v", dl)
+		}
+		if !strings.Contains(dl.Error, msg) {
+			t.Fatalf("expected error %q, got %q", msg, dl.Error)
+		}
+	}
+}
+
+func TestDeadLetterTopic(t *testing.T) {
+	e := New(WithDeadLetterTopic("dead"), WithErrorHandler(nil))
+	r := &deadLetterRecorder{}
+	e.SubscribeSync("dead", r.record)
+	// a failing dead-letter subscriber does not dead-letter again
+	e.SubscribeSync("dead", func(dl DeadLetter) error {
+		return errors.New("fail")
+	})
+	e.SubscribeSync("orders.*", func(id string) error {
+		return errors.New("fail")
+	})
+	e.Publish("orders.created", "o-1")
+	e.Publish("orders.paid", "o-2")
+
+	got := r.letters()
+	if len(got) != 2 || got[0].Topic != "orders.created" || got[1].Topic != "orders.paid" {
+		t.Fatalf("unexpected dead letters %v", got)
+	}
+	if got[0].Pattern != "orders.*" {
+		t.Fatalf("expected pattern orders.*, got %q", got[0].Pattern)
+	}
+}
+
+func TestDeadLetterRequest(t *testing.T) {
+	e := New(WithDeadLetter())
+	r := &deadLetterRecorder{}
+	e.SubscribeSync("price.dlq", r.record)
+	e.SubscribeSync("price", func(id string) (int, error) {
+		return 0, errors.New("unknown")
+	})
+	// the requester receives the error instead
+	if _, err := e.Request(context.Background(), "price", "p-1"); err == nil {
+		t.Fatal("expected an error")
+	}
+	if got := r.letters(); len(got) != 0 {
+		t.Fatalf("expected no dead letters, got %v", got)
+	}
+}
+
+func TestDeadLetterBatch(t *testing.T) {
+	e := New(WithDeadLetter(), WithErrorHandler(nil))
+	r := &deadLetterRecorder{}
+	e.SubscribeSync("metrics.cpu.dlq", r.record)
+	e.SubscribeSync("metrics.mem.dlq", r.record)
+	e.SubscribeBatch("metrics.*", func(batch [][]interface{}) error {
+		return errors.New("store down")
+	}, 2, 0)
+	e.Publish("metrics.cpu", 1)
+	e.Publish("metrics.mem", 2)
+	time.Sleep(50 * time.Millisecond)
+
+	got := r.letters()
+	if len(got) != 2 || got[0].Topic != "metrics.cpu" || got[1].Topic != "metrics.mem" {
+		t.Fatalf("expected a dead letter per event, got %v", got)
+	}
+	if !reflect.DeepEqual(got[1].Args, []interface{}{2}) {
+		t.Fatalf("unexpected args %v", got[1].Args)
+	}
+}
@@ -0,0 +1,57 @@
+package eventbus
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+)
+
+type ErrorHandler func(err error)
+
+// HandlerError describes a failed delivery to a subscriber.
+// Panic and Stack are set when the failure was a recovered panic.
+type HandlerError struct {
+	Topic   string
+	Handler string
+	Err     error
+	Panic   any
+	Stack   []byte
+}
+
+func (e *HandlerError) Error() string {
+	if e.Panic != nil {
+		return fmt.Sprintf("handler %s on topic %q panicked: %v", e.Handler, e.Topic, e.Panic)
+	}
+	return fmt.Sprintf("handler %s on topic %q failed: %v", e.Handler, e.Topic, e.Err)
+}
+
+func (e *HandlerError) Unwrap() error {
+	return e.Err
+}
+
+func newPanicError(topic, handler string, r any) *HandlerError {
+	err, ok := r.(error)
+	if !ok {
+		err = fmt.Errorf("%v", r)
+	}
+	return &HandlerError{
+		Topic:   topic,
+		Handler: handler,
+		Err:     err,
+		Panic:   r,
+		Stack:   debug.Stack(),
+	}
+}
+
+func defaultErrorHandler(err error) {
+	log.Printf("eventbus: %v", err)
+}
+
+func funcName(fn reflect.Value) string {
+	if f := runtime.FuncForPC(fn.Pointer()); f != nil {
+		return f.Name()
+	}
+	return fmt.Sprintf("%#x", fn.Pointer())
+}
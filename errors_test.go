@@ -0,0 +1,143 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/danielhookx/fission"
+)
+
+func TestSyncHandlerPanic(t *testing.T) {
+	var errs []error
+	e := New(WithErrorHandler(func(err error) {
+		errs = append(errs, err)
+	}))
+	topic := "testpub1"
+	called := 0
+	e.SubscribeSync(topic, func(name string) {
+		called++
+	})
+	e.SubscribeSync(topic, func(name string) {
+		panic("boom")
+	})
+	e.SubscribeSync(topic, func(name string) {
+		called++
+	})
+	e.Publish(topic, "jack")
+	if called != 2 {
+		t.Fatalf("expected remaining handlers to run, got %d", called)
+	}
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %d", len(errs))
+	}
+	var herr *HandlerError
+	if !errors.As(errs[0], &herr) {
+		t.Fatalf("expected HandlerError, got %v", errs[0])
+	}
+	if herr.Topic != topic || herr.Panic != "boom" || herr.Handler == "" || len(herr.Stack) == 0 {
+		t.Fatalf("unexpected error content %+v", herr)
+	}
+}
+
+func TestAsyncHandlerPanic(t *testing.T) {
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	var got error
+	e := New(WithErrorHandler(func(err error) {
+		defer wg.Done()
+		got = err
+	}))
+	topic := "testpub1"
+	e.Subscribe(topic, func(name string) {
+		panic(errors.New("boom"))
+	})
+	e.Publish(topic, "jack")
+	wg.Wait()
+	var herr *HandlerError
+	if !errors.As(got, &herr) || herr.Err.Error() != "boom" {
+		t.Fatalf("unexpected error %v", got)
+	}
+}
+
+type panicDist struct {
+	mockDist
+}
+
+func (m *panicDist) Dist(data any) error {
+	panic("custom boom")
+}
+
+func TestSubscribeWithPanic(t *testing.T) {
+	var errs []error
+	e := New(WithErrorHandler(func(err error) {
+		errs = append(errs, err)
+	}))
+	topic := "testpub1"
+	called := false
+	e.SubscribeWith(topic, "key1", func(key any) fission.Distribution {
+		return &panicDist{mockDist{key: key.(string)}}
+	})
+	e.SubscribeSync(topic, func(name string) {
+		called = true
+	})
+	e.Publish(topic, "jack")
+	if !called || len(errs) != 1 {
+		t.Fatalf("expected delivery to continue after panic, called=%v errs=%v", called, errs)
+	}
+}
+
+func TestPublishMismatchReported(t *testing.T) {
+	var errs []error
+	e := New(WithErrorHandler(func(err error) {
+		errs = append(errs, err)
+	}))
+	topic := "testpub1"
+	e.SubscribeSync(topic, func(name string) {})
+	e.Publish(topic, 1)
+	var mismatch *SignatureMismatchError
+	if len(errs) != 1 || !errors.As(errs[0], &mismatch) {
+		t.Fatalf("expected mismatch error, got %v", errs)
+	}
+}
+
+func TestHandlerErrorReported(t *testing.T) {
+	var lock sync.Mutex
+	var errs []error
+	e := New(WithErrorHandler(func(err error) {
+		lock.Lock()
+		errs = append(errs, err)
+		lock.Unlock()
+	}))
+	topic := "testpub1"
+	e.SubscribeSync(topic, func(name string) error {
+		return errors.New("sync boom")
+	})
+	attempts := 0
+	done := make(chan struct{})
+	e.Subscribe(topic, func(name string) error {
+		if attempts++; attempts == 3 {
+			close(done)
+		}
+		return errors.New("async boom")
+	}, WithRetry(RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond}))
+	e.Publish(topic, "jack")
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("handler was not retried")
+	}
+	e.Drain(context.Background())
+	// the async failure is reported once, after its retries
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %v", errs)
+	}
+	for i, msg := range []string{"sync boom", "async boom"} {
+		var herr *HandlerError
+		if !errors.As(errs[i], &herr) || herr.Topic != topic || herr.Handler == "" || herr.Err.Error() != msg {
+			t.Fatalf("unexpected error %v", errs[i])
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package eventbus
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ExpvarCollector is a MetricsCollector exposing its measurements as JSON.
+// It is an expvar.Var, so it can be published with expvar.Publish to appear
+// in /debug/vars, and an http.Handler serving the same JSON.
+type ExpvarCollector struct {
+	*metricsStore
+}
+
+// NewExpvarCollector returns an ExpvarCollector with the given histogram buckets
+// in seconds, DefaultBuckets when none are given.
+func NewExpvarCollector(buckets ...float64) *ExpvarCollector {
+	return &ExpvarCollector{
+		metricsStore: newMetricsStore(buckets),
+	}
+}
+
+// String returns the MetricsSnapshot of the collector in JSON.
+func (c *ExpvarCollector) String() string {
+	b, err := json.Marshal(c.Snapshot())
+	if err != nil {
+		return "{}"
+	}
+	return string(b)
+}
+
+func (c *ExpvarCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Write([]byte(c.String()))
+}
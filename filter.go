@@ -0,0 +1,141 @@
+package eventbus
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// FieldFilter matches the events whose argument at index Arg equals Value.
+// When Field is set the argument's field is compared instead, Field is a
+// dot separated path of struct fields or string map keys.
+// Field filters are sent to the remote endpoint of an RPCProxy, so that events
+// not matching them are not sent over the network.
+type FieldFilter struct {
+	Arg   int
+	Field string
+	Value any
+}
+
+func (f FieldFilter) match(args []interface{}) bool {
+	if f.Arg < 0 || f.Arg >= len(args) {
+		return false
+	}
+	v := reflect.ValueOf(args[f.Arg])
+	if f.Field != "" {
+		for _, name := range strings.Split(f.Field, ".") {
+			for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+				if v.IsNil() {
+					return false
+				}
+				v = v.Elem()
+			}
+			switch v.Kind() {
+			case reflect.Struct:
+				v = v.FieldByName(name)
+			case reflect.Map:
+				if v.Type().Key().Kind() != reflect.String {
+					return false
+				}
+				v = v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+			default:
+				return false
+			}
+			if !v.IsValid() {
+				return false
+			}
+		}
+	}
+	if !v.IsValid() {
+		return f.Value == nil
+	}
+	if !v.CanInterface() {
+		return false
+	}
+	return reflect.DeepEqual(v.Interface(), f.Value)
+}
+
+func matchFieldFilters(filters []FieldFilter, args []interface{}) bool {
+	for _, f := range filters {
+		if !f.match(args) {
+			return false
+		}
+	}
+	return true
+}
+
+// filter combines the filters of a subscription.
+func (o *subscribeOptions) filter() func(args []interface{}) bool {
+	if len(o.predicates) == 0 && len(o.fieldFilters) == 0 {
+		return nil
+	}
+	predicates, fieldFilters := o.predicates, o.fieldFilters
+	return func(args []interface{}) bool {
+		if !matchFieldFilters(fieldFilters, args) {
+			return false
+		}
+		for _, p := range predicates {
+			if !p(args) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// accepts reports whether ev is delivered to s. A panicking filter is reported
+// and does not accept the event.
+func (s *subscription) accepts(ev *event) (ok bool) {
+	if ev.reply != nil && !s.responder {
+		return false
+	}
+	if s.filter == nil {
+		return true
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			s.bus.onError(newPanicError(s.topic, fmt.Sprint(s.key), r))
+			ok = false
+		}
+	}()
+	return s.filter(ev.args)
+}
+
+// remoteFilters holds the field filters of every subscription a peer made to a
+// topic by subscription id, an event is forwarded when one of them matches.
+type remoteFilters struct {
+	lock sync.RWMutex
+	sets map[uint64][]FieldFilter
+}
+
+func newRemoteFilters() *remoteFilters {
+	return &remoteFilters{
+		sets: make(map[uint64][]FieldFilter),
+	}
+}
+
+func (f *remoteFilters) add(id uint64, filters []FieldFilter) {
+	f.lock.Lock()
+	f.sets[id] = filters
+	f.lock.Unlock()
+}
+
+// remove drops the filters of subscription id and returns the number of subscriptions left.
+func (f *remoteFilters) remove(id uint64) int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	delete(f.sets, id)
+	return len(f.sets)
+}
+
+func (f *remoteFilters) match(args []interface{}) bool {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	for _, filters := range f.sets {
+		if matchFieldFilters(filters, args) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package eventbus
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+type order struct {
+	ID     string
+	Tenant string
+	Labels map[string]string
+}
+
+func TestFieldFilterMatch(t *testing.T) {
+	o := order{ID: "1", Tenant: "acme", Labels: map[string]string{"region": "eu"}}
+	tests := []struct {
+		filter FieldFilter
+		args   []interface{}
+		match  bool
+	}{
+		{FieldFilter{Arg: 0, Value: "jack"}, []interface{}{"jack"}, true},
+		{FieldFilter{Arg: 0, Value: "jack"}, []interface{}{"rose"}, false},
+		{FieldFilter{Arg: 1, Value: 1}, []interface{}{"jack"}, false},
+		{FieldFilter{Arg: 0, Field: "Tenant", Value: "acme"}, []interface{}{o}, true},
+		{FieldFilter{Arg: 0, Field: "Tenant", Value: "acme"}, []interface{}{&o}, true},
+		{FieldFilter{Arg: 0, Field: "Tenant", Value: "other"}, []interface{}{o}, false},
+		{FieldFilter{Arg: 0, Field: "Labels.region", Value: "eu"}, []interface{}{o}, true},
+		{FieldFilter{Arg: 0, Field: "Labels.zone", Value: "eu"}, []interface{}{o}, false},
+		{FieldFilter{Arg: 0, Field: "Missing", Value: "acme"}, []interface{}{o}, false},
+		{FieldFilter{Arg: 0, Field: "Tenant", Value: "acme"}, []interface{}{(*order)(nil)}, false},
+		{FieldFilter{Arg: 0, Value: nil}, []interface{}{nil}, true},
+	}
+	for i, tt := range tests {
+		if got := tt.filter.match(tt.args); got != tt.match {
+			t.Errorf("%d: expected %v, got %v", i, tt.match, got)
+		}
+	}
+}
+
+func TestSubscribeFilter(t *testing.T) {
+	e := New()
+	topic := "orders"
+	var got []string
+	e.SubscribeSync(topic, func(o order) {
+		got = append(got, o.ID)
+	}, WithFieldFilter(FieldFilter{Field: "Tenant", Value: "acme"}), WithFilter(func(args []interface{}) bool {
+		return args[0].(order).ID != "3"
+	}))
+	e.Publish(topic, order{ID: "1", Tenant: "acme"})
+	e.Publish(topic, order{ID: "2", Tenant: "other"})
+	e.Publish(topic, order{ID: "3", Tenant: "acme"})
+
+	if !reflect.DeepEqual(got, []string{"1"}) {
+		t.Fatalf("unexpected deliveries %v", got)
+	}
+}
+
+func TestSubscribeFilterBeforeDispatch(t *testing.T) {
+	var executed atomic.Int64
+	pool := NewWorkerPool(1, 10)
+	defer pool.Close()
+	e := New(WithWorkerPool(pool))
+	topic := "orders"
+	e.Subscribe(topic, func(o order) {}, WithFieldFilter(FieldFilter{Field: "Tenant", Value: "acme"}))
+	once, _ := e.SubscribeOnce(topic, func(o order) {
+		executed.Add(1)
+	}, WithFilter(func(args []interface{}) bool {
+		return args[0].(order).Tenant == "acme"
+	}))
+	e.Publish(topic, order{ID: "1", Tenant: "other"})
+	if !once.Active() {
+		t.Fatal("filtered event should not consume a once subscription")
+	}
+	if n := pool.Stats().Submitted; n != 0 {
+		t.Fatalf("filtered events should not be dispatched, %d tasks submitted", n)
+	}
+	e.Publish(topic, order{ID: "2", Tenant: "acme"})
+	if err := e.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if executed.Load() != 1 || once.Active() {
+		t.Fatalf("expected a single delivery, got %d", executed.Load())
+	}
+}
+
+func TestSubscribeFilterPanic(t *testing.T) {
+	var errs []error
+	e := New(WithErrorHandler(func(err error) {
+		errs = append(errs, err)
+	}))
+	topic := "testpub1"
+	called := false
+	e.SubscribeSync(topic, func(name string) {
+		t.Error("event should not be delivered when the filter panics")
+	}, WithFilter(func(args []interface{}) bool {
+		panic("boom")
+	}))
+	e.SubscribeSync(topic, func(name string) {
+		called = true
+	})
+	e.Publish(topic, "jack")
+	if !called || len(errs) != 1 {
+		t.Fatalf("expected delivery to continue after panic, called=%v errs=%v", called, errs)
+	}
+}
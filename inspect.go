@@ -0,0 +1,117 @@
+package eventbus
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SubscriberMode tells how a subscriber receives events.
+type SubscriberMode int
+
+const (
+	SubscriberSync SubscriberMode = iota
+	SubscriberAsync
+	SubscriberOrdered
+	// SubscriberCustom is a distribution registered with SubscribeWith.
+	SubscriberCustom
+	SubscriberBatch
+)
+
+func (m SubscriberMode) String() string {
+	switch m {
+	case SubscriberSync:
+		return "sync"
+	case SubscriberAsync:
+		return "async"
+	case SubscriberOrdered:
+		return "ordered"
+	case SubscriberCustom:
+		return "custom"
+	case SubscriberBatch:
+		return "batch"
+	}
+	return "unknown"
+}
+
+type SubscriberInfo struct {
+	// Pattern is the topic pattern subscribed to.
+	Pattern string
+	// Key is the key given to SubscribeWith, it is nil for handler subscriptions.
+	Key any
+	// Handler is the name of the handler function, or the formatted key of a custom distribution.
+	Handler  string
+	Mode     SubscriberMode
+	Priority int
+	// Remote is the URL of the peer the events are forwarded to by an RPCProxy.
+	Remote string
+}
+
+// Topics returns the subscribed topic patterns in lexical order.
+func (bus *EventBus) Topics() []string {
+	topics := bus.subs.topics()
+	sort.Strings(topics)
+	return topics
+}
+
+// Subscribers returns the subscribers a publish to topic reaches in delivery order.
+// When topic contains wildcards the subscribers of that exact pattern are returned.
+func (bus *EventBus) Subscribers(topic string) []SubscriberInfo {
+	var subs []*subscription
+	if validateTopic(topic) != nil {
+		if c := bus.topics.get(topic); c != nil {
+			subs = c.snapshot()
+		}
+	} else {
+		var groups [][]*subscription
+		for _, node := range bus.topics.match(topic) {
+			groups = append(groups, node.center.snapshot())
+		}
+		subs = mergeByPriority(groups)
+	}
+	infos := make([]SubscriberInfo, 0, len(subs))
+	for _, s := range subs {
+		infos = append(infos, s.info())
+	}
+	return infos
+}
+
+// HasSubscribers reports whether a publish to topic would reach any subscriber,
+// or whether a pattern has subscribers when topic contains wildcards.
+func (bus *EventBus) HasSubscribers(topic string) bool {
+	return len(bus.Subscribers(topic)) > 0
+}
+
+func (s *subscription) info() SubscriberInfo {
+	info := SubscriberInfo{
+		Pattern:  s.topic,
+		Priority: s.priority,
+	}
+	switch d := s.Distribution.(type) {
+	case *syncDistribution:
+		info.Mode = SubscriberSync
+		info.Handler = funcName(d.fn)
+	case *asyncDistribution:
+		info.Mode = SubscriberAsync
+		info.Handler = funcName(d.fn)
+	case *queuedDistribution:
+		info.Mode = SubscriberAsync
+		if d.ordered {
+			info.Mode = SubscriberOrdered
+		}
+		info.Handler = funcName(d.fn)
+	case *batchDistribution:
+		info.Mode = SubscriberBatch
+		info.Handler = funcName(d.fn)
+	case *waitDistribution:
+		info.Mode = SubscriberSync
+		info.Handler = "WaitFor"
+	case *safeDistribution:
+		info.Mode = SubscriberCustom
+		info.Key = s.key
+		info.Handler = fmt.Sprint(s.key)
+		if nd, ok := d.Distribution.(*netPublishDist); ok {
+			info.Remote = nd.args.RemoteURL
+		}
+	}
+	return info
+}
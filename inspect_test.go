@@ -0,0 +1,80 @@
+package eventbus
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTopics(t *testing.T) {
+	e := New()
+	e.Subscribe("orders.*", a)
+	sub, _ := e.SubscribeSync("users", b)
+	e.SubscribeWith("orders.created", "mock", createMockDistHandlerFunc)
+	if topics := e.Topics(); !reflect.DeepEqual(topics, []string{"orders.*", "orders.created", "users"}) {
+		t.Fatalf("unexpected topics %v", topics)
+	}
+	sub.Unsubscribe()
+	if topics := e.Topics(); !reflect.DeepEqual(topics, []string{"orders.*", "orders.created"}) {
+		t.Fatalf("unexpected topics %v", topics)
+	}
+}
+
+func TestSubscribers(t *testing.T) {
+	e := New()
+	e.Subscribe("orders.*", a)
+	e.SubscribeSync("orders.created", b, WithPriority(1))
+	e.SubscribeOrdered("orders.#", c)
+	e.SubscribeWith("orders.created", "mock", createMockDistHandlerFunc)
+
+	subs := e.Subscribers("orders.created")
+	if len(subs) != 4 {
+		t.Fatalf("expected 4 subscribers, got %d", len(subs))
+	}
+	expected := []struct {
+		pattern string
+		mode    SubscriberMode
+		handler string
+	}{
+		{"orders.created", SubscriberSync, ".b"},
+		{"orders.#", SubscriberOrdered, ".c"},
+		{"orders.created", SubscriberCustom, "mock"},
+		{"orders.*", SubscriberAsync, ".a"},
+	}
+	for i, exp := range expected {
+		s := subs[i]
+		if s.Pattern != exp.pattern || s.Mode != exp.mode || !strings.HasSuffix(s.Handler, exp.handler) {
+			t.Fatalf("%d: unexpected subscriber %+v", i, s)
+		}
+	}
+	if subs[2].Key != "mock" || subs[0].Key != nil || subs[0].Priority != 1 {
+		t.Fatalf("unexpected subscribers %+v", subs)
+	}
+
+	if subs := e.Subscribers("orders.*"); len(subs) != 1 || subs[0].Pattern != "orders.*" {
+		t.Fatalf("expected the subscribers of the pattern, got %+v", subs)
+	}
+	if !e.HasSubscribers("orders.cancelled") || e.HasSubscribers("users") || e.HasSubscribers("users.*") {
+		t.Fatal("unexpected HasSubscribers result")
+	}
+}
+
+func TestRPCProxySubscribers(t *testing.T) {
+	pub, sub := newTestProxyPair(t)
+	if _, err := sub.Subscribe("orders.*", a); err != nil {
+		t.Fatal(err)
+	}
+	if !pub.HasSubscribers("orders.created") {
+		t.Fatal("expected the remote subscriber to be listed")
+	}
+	subs := pub.Subscribers("orders.created")
+	if len(subs) != 1 || subs[0].Mode != SubscriberCustom || !strings.HasSuffix(subs[0].Remote, "b.sock") {
+		t.Fatalf("unexpected subscribers %+v", subs)
+	}
+	if subs := sub.Subscribers("orders.created"); len(subs) != 1 || subs[0].Remote != "" {
+		t.Fatalf("unexpected local subscribers %+v", subs)
+	}
+	if topics := sub.Topics(); !reflect.DeepEqual(topics, []string{"orders.*"}) {
+		t.Fatalf("unexpected topics %v", topics)
+	}
+}
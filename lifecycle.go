@@ -0,0 +1,140 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var ErrClosed = errors.New("eventbus is closed")
+
+// inflight counts the deliveries that have not completed yet.
+type inflight struct {
+	lock sync.Mutex
+	n    int
+	idle chan struct{}
+}
+
+func (f *inflight) add() {
+	f.lock.Lock()
+	f.n++
+	f.lock.Unlock()
+}
+
+func (f *inflight) done() {
+	f.lock.Lock()
+	f.n--
+	if f.n == 0 && f.idle != nil {
+		close(f.idle)
+		f.idle = nil
+	}
+	f.lock.Unlock()
+}
+
+func (f *inflight) wait(ctx context.Context) error {
+	f.lock.Lock()
+	if f.n == 0 {
+		f.lock.Unlock()
+		return nil
+	}
+	if f.idle == nil {
+		f.idle = make(chan struct{})
+	}
+	idle := f.idle
+	f.lock.Unlock()
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// track wraps execute so that Drain waits for the tasks it runs.
+func (bus *EventBus) track(execute func(task func())) func(task func()) {
+	return func(task func()) {
+		bus.inflight.add()
+		execute(func() {
+			defer bus.inflight.done()
+			if bus.metrics != nil {
+				bus.metrics.AsyncStarted()
+				defer bus.metrics.AsyncFinished()
+			}
+			task()
+		})
+	}
+}
+
+// Drain stops accepting publishes and waits until the outstanding
+// deliveries are done or ctx expires. Pending scheduled events are
+// published or discarded according to the schedule policy first, the
+// events held back by rate limits and batches are delivered at the end.
+func (bus *EventBus) Drain(ctx context.Context) error {
+	bus.stopScheduler()
+	bus.closeLock.Lock()
+	if !bus.closed {
+		bus.closed = true
+		close(bus.shutdown)
+	}
+	bus.closeLock.Unlock()
+	if err := bus.inflight.wait(ctx); err != nil {
+		return err
+	}
+	bus.flushLimiters()
+	bus.flushBatches()
+	return bus.inflight.wait(ctx)
+}
+
+// Close drains the bus and closes every distribution. Distributions are closed
+// even when ctx expires before the outstanding deliveries are done.
+func (bus *EventBus) Close(ctx context.Context) error {
+	err := bus.Drain(ctx)
+	for _, sub := range bus.subs.all() {
+		if !sub.active.CompareAndSwap(true, false) {
+			continue
+		}
+		bus.detach(sub)
+		if sub.limiter != nil {
+			sub.limiter.stop()
+		}
+		// distributions created through SubscribeWith are shared by key and closed by the manager
+		if _, ok := sub.Distribution.(*safeDistribution); !ok {
+			sub.Distribution.Close()
+		}
+	}
+	bus.dm.Destroy()
+	return err
+}
+
+// shutdownProvider is implemented by the buses handing the channel closed by Drain over to an RPCProxy.
+type shutdownProvider interface {
+	busShutdown() <-chan struct{}
+}
+
+func (bus *EventBus) busShutdown() <-chan struct{} {
+	return bus.shutdown
+}
+
+// shutdownOf returns the channel closed when bus is drained, nil when it has none.
+func shutdownOf(bus Eventbus) <-chan struct{} {
+	if sp, ok := bus.(shutdownProvider); ok {
+		return sp.busShutdown()
+	}
+	return nil
+}
+
+// acquire prevents the bus from being closed until release is called.
+func (bus *EventBus) acquire() error {
+	bus.closeLock.RLock()
+	if bus.closed {
+		bus.closeLock.RUnlock()
+		return ErrClosed
+	}
+	bus.inflight.add()
+	bus.closeLock.RUnlock()
+	return nil
+}
+
+func (bus *EventBus) release() {
+	bus.inflight.done()
+}
@@ -0,0 +1,100 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/danielhookx/fission"
+)
+
+type closeDist struct {
+	mockDist
+	closed *atomic.Int64
+}
+
+func (m *closeDist) Close() error {
+	m.closed.Add(1)
+	return nil
+}
+
+func TestDrainWaitsForAsyncHandlers(t *testing.T) {
+	e := New()
+	topic := "testpub1"
+	var done atomic.Int64
+	e.Subscribe(topic, func(i int) {
+		time.Sleep(time.Millisecond * 20)
+		done.Add(1)
+	})
+	e.SubscribeOrdered(topic, func(i int) {
+		time.Sleep(time.Millisecond * 5)
+		done.Add(1)
+	})
+	for i := 0; i < 5; i++ {
+		e.Publish(topic, i)
+	}
+	if err := e.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if done.Load() != 10 {
+		t.Fatalf("expected 10 deliveries before drain returns, got %d", done.Load())
+	}
+	if err := e.PublishE(topic, 1); !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+}
+
+func TestDrainTimeout(t *testing.T) {
+	e := New()
+	topic := "testpub1"
+	release := make(chan struct{})
+	defer close(release)
+	e.Subscribe(topic, func(i int) {
+		<-release
+	})
+	e.Publish(topic, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	if err := e.Drain(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestClose(t *testing.T) {
+	e := New()
+	topic := "testpub1"
+	var closed atomic.Int64
+	sub, _ := e.SubscribeWith(topic, "key1", func(key any) fission.Distribution {
+		return &closeDist{mockDist: mockDist{key: key.(string)}, closed: &closed}
+	})
+	e.SubscribeWith("testpub2", "key1", nil)
+	syncSub, _ := e.SubscribeSync(topic, func(i int) {})
+
+	waitErr := make(chan error)
+	go func() {
+		_, err := e.WaitFor(context.Background(), "testpub3")
+		waitErr <- err
+	}()
+	time.Sleep(time.Millisecond * 10)
+
+	if err := e.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if closed.Load() != 1 {
+		t.Fatalf("expected shared distribution to be closed once, got %d", closed.Load())
+	}
+	if sub.Active() || syncSub.Active() {
+		t.Fatal("expected subscriptions to be inactive")
+	}
+	if err := <-waitErr; !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected WaitFor to return ErrClosed, got %v", err)
+	}
+	if _, err := e.Subscribe(topic, func(i int) {}); !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+	if _, err := e.SubscribeWith(topic, "key2", createMockDistHandlerFunc); !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+}
@@ -0,0 +1,232 @@
+package eventbus
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// MetricsCollector receives the measurements of a bus, set with WithMetrics.
+// Its methods are called concurrently.
+type MetricsCollector interface {
+	// Published is called for every publish to topic with the error returned to the publisher.
+	Published(topic string, err error)
+	// Delivered is called for every attempt to deliver an event to a subscriber.
+	Delivered(m DeliveryMetrics)
+	// AsyncStarted and AsyncFinished are called around every task running
+	// asynchronous deliveries on a goroutine or the worker pool.
+	AsyncStarted()
+	AsyncFinished()
+}
+
+// DeliveryMetrics measure a delivery attempt to a subscriber.
+type DeliveryMetrics struct {
+	Topic      string
+	Pattern    string
+	Subscriber string
+	// Latency is the time from the publish to the start of the delivery. It is zero
+	// for events delivered without a publish, like retained or replayed ones.
+	Latency time.Duration
+	// Duration is the time the handler took, including its middleware.
+	Duration time.Duration
+	Err      error
+}
+
+// DefaultBuckets are the upper bounds in seconds of the latency and duration
+// histograms of the collectors created without buckets.
+var DefaultBuckets = []float64{.0001, .0005, .001, .005, .01, .05, .1, .5, 1, 5}
+
+// MetricsSnapshot are the measurements aggregated by a collector.
+type MetricsSnapshot struct {
+	Topics       []TopicMetrics
+	Subscribers  []SubscriberMetrics
+	AsyncRunning int64
+	AsyncStarted uint64
+}
+
+type TopicMetrics struct {
+	Topic     string
+	Published uint64
+	Errors    uint64
+}
+
+type SubscriberMetrics struct {
+	Topic      string
+	Pattern    string
+	Subscriber string
+	Delivered  uint64
+	Errors     uint64
+	Latency    HistogramMetrics
+	Duration   HistogramMetrics
+}
+
+// HistogramMetrics count the observations in seconds up to each of Buckets.
+type HistogramMetrics struct {
+	Buckets []float64
+	// Counts are cumulative, Counts[i] is the number of observations up to Buckets[i].
+	Counts []uint64
+	Sum    float64
+	Count  uint64
+}
+
+type histogram struct {
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+func newHistogram(buckets []float64) *histogram {
+	return &histogram{
+		buckets: buckets,
+		counts:  make([]uint64, len(buckets)),
+	}
+}
+
+func (h *histogram) observe(d time.Duration) {
+	v := d.Seconds()
+	if i := sort.SearchFloat64s(h.buckets, v); i < len(h.counts) {
+		h.counts[i]++
+	}
+	h.sum += v
+	h.count++
+}
+
+func (h *histogram) snapshot() HistogramMetrics {
+	m := HistogramMetrics{
+		Buckets: h.buckets,
+		Counts:  make([]uint64, len(h.counts)),
+		Sum:     h.sum,
+		Count:   h.count,
+	}
+	var n uint64
+	for i, c := range h.counts {
+		n += c
+		m.Counts[i] = n
+	}
+	return m
+}
+
+type deliveryKey struct {
+	topic      string
+	pattern    string
+	subscriber string
+}
+
+type deliveryStats struct {
+	delivered uint64
+	errors    uint64
+	latency   *histogram
+	duration  *histogram
+}
+
+// metricsStore aggregates the measurements of the collectors exporting them.
+type metricsStore struct {
+	buckets []float64
+
+	lock         sync.Mutex
+	topics       map[string]*TopicMetrics
+	deliveries   map[deliveryKey]*deliveryStats
+	asyncRunning int64
+	asyncStarted uint64
+}
+
+func newMetricsStore(buckets []float64) *metricsStore {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &metricsStore{
+		buckets:    buckets,
+		topics:     make(map[string]*TopicMetrics),
+		deliveries: make(map[deliveryKey]*deliveryStats),
+	}
+}
+
+func (s *metricsStore) Published(topic string, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	t, ok := s.topics[topic]
+	if !ok {
+		t = &TopicMetrics{Topic: topic}
+		s.topics[topic] = t
+	}
+	t.Published++
+	if err != nil {
+		t.Errors++
+	}
+}
+
+func (s *metricsStore) Delivered(m DeliveryMetrics) {
+	key := deliveryKey{topic: m.Topic, pattern: m.Pattern, subscriber: m.Subscriber}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	d, ok := s.deliveries[key]
+	if !ok {
+		d = &deliveryStats{
+			latency:  newHistogram(s.buckets),
+			duration: newHistogram(s.buckets),
+		}
+		s.deliveries[key] = d
+	}
+	d.delivered++
+	if m.Err != nil {
+		d.errors++
+	}
+	d.latency.observe(m.Latency)
+	d.duration.observe(m.Duration)
+}
+
+func (s *metricsStore) AsyncStarted() {
+	s.lock.Lock()
+	s.asyncRunning++
+	s.asyncStarted++
+	s.lock.Unlock()
+}
+
+func (s *metricsStore) AsyncFinished() {
+	s.lock.Lock()
+	s.asyncRunning--
+	s.lock.Unlock()
+}
+
+// Snapshot returns the measurements so far, sorted by topic and subscriber.
+func (s *metricsStore) Snapshot() MetricsSnapshot {
+	s.lock.Lock()
+	snapshot := MetricsSnapshot{
+		Topics:       make([]TopicMetrics, 0, len(s.topics)),
+		Subscribers:  make([]SubscriberMetrics, 0, len(s.deliveries)),
+		AsyncRunning: s.asyncRunning,
+		AsyncStarted: s.asyncStarted,
+	}
+	for _, t := range s.topics {
+		snapshot.Topics = append(snapshot.Topics, *t)
+	}
+	for key, d := range s.deliveries {
+		snapshot.Subscribers = append(snapshot.Subscribers, SubscriberMetrics{
+			Topic:      key.topic,
+			Pattern:    key.pattern,
+			Subscriber: key.subscriber,
+			Delivered:  d.delivered,
+			Errors:     d.errors,
+			Latency:    d.latency.snapshot(),
+			Duration:   d.duration.snapshot(),
+		})
+	}
+	s.lock.Unlock()
+	sort.Slice(snapshot.Topics, func(i, j int) bool {
+		return snapshot.Topics[i].Topic < snapshot.Topics[j].Topic
+	})
+	sort.Slice(snapshot.Subscribers, func(i, j int) bool {
+		a, b := snapshot.Subscribers[i], snapshot.Subscribers[j]
+		if a.Topic != b.Topic {
+			return a.Topic < b.Topic
+		}
+		if a.Pattern != b.Pattern {
+			return a.Pattern < b.Pattern
+		}
+		return a.Subscriber < b.Subscriber
+	})
+	return snapshot
+}
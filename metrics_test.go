@@ -0,0 +1,119 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	c := NewPrometheusCollector()
+	e := New(WithMetrics(c), WithErrorHandler(nil))
+	e.SubscribeSync("orders.*", func(id string) error {
+		if id == "bad" {
+			return errors.New("invalid")
+		}
+		return nil
+	})
+	e.Subscribe("orders.created", func(id string) {
+		time.Sleep(time.Millisecond)
+	})
+	e.Publish("orders.created", "o-1")
+	e.Publish("orders.created", "bad")
+	e.Publish("orders.paid", "o-1")
+	e.PublishE("orders.paid", 1)
+	e.Drain(context.Background())
+
+	s := c.Snapshot()
+	expectedTopics := []TopicMetrics{
+		{Topic: "orders.created", Published: 2},
+		{Topic: "orders.paid", Published: 2, Errors: 1},
+	}
+	if len(s.Topics) != len(expectedTopics) {
+		t.Fatalf("expected topics %v, got %v", expectedTopics, s.Topics)
+	}
+	for i, topic := range expectedTopics {
+		if s.Topics[i] != topic {
+			t.Fatalf("expected %v, got %v", topic, s.Topics[i])
+		}
+	}
+	if len(s.Subscribers) != 3 {
+		t.Fatalf("expected 3 subscribers, got %v", s.Subscribers)
+	}
+	syncSub := s.Subscribers[0]
+	if syncSub.Topic != "orders.created" || syncSub.Pattern != "orders.*" || syncSub.Delivered != 2 || syncSub.Errors != 1 {
+		t.Fatalf("unexpected subscriber metrics %+v", syncSub)
+	}
+	async := s.Subscribers[1]
+	if async.Pattern != "orders.created" || async.Delivered != 2 || async.Duration.Count != 2 {
+		t.Fatalf("unexpected subscriber metrics %+v", async)
+	}
+	if async.Duration.Sum < 0.002 {
+		t.Fatalf("expected the handler duration to be measured, got %v", async.Duration.Sum)
+	}
+	if s.AsyncStarted != 2 || s.AsyncRunning != 0 {
+		t.Fatalf("expected 2 finished async tasks, got %d started and %d running", s.AsyncStarted, s.AsyncRunning)
+	}
+}
+
+func TestPrometheusCollector(t *testing.T) {
+	c := NewPrometheusCollector(0.5, 0.1)
+	e := New(WithMetrics(c))
+	e.SubscribeSync("orders.created", func(id string) {})
+	e.Publish("orders.created", `o"1`)
+
+	rec := httptest.NewRecorder()
+	c.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Fatalf("unexpected content type %s", ct)
+	}
+	body := rec.Body.String()
+	labels := `topic="orders.created",pattern="orders.created",subscriber="github.com/danielhookx/eventbus.TestPrometheusCollector.func1"`
+	for _, line := range []string{
+		"# TYPE eventbus_published_total counter",
+		`eventbus_published_total{topic="orders.created"} 1`,
+		`eventbus_publish_errors_total{topic="orders.created"} 0`,
+		"eventbus_delivered_total{" + labels + "} 1",
+		"# TYPE eventbus_handler_duration_seconds histogram",
+		"eventbus_handler_duration_seconds_bucket{" + labels + `,le="0.1"} 1`,
+		"eventbus_handler_duration_seconds_bucket{" + labels + `,le="0.5"} 1`,
+		"eventbus_handler_duration_seconds_bucket{" + labels + `,le="+Inf"} 1`,
+		"eventbus_handler_duration_seconds_count{" + labels + "} 1",
+		"eventbus_async_tasks 0",
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Fatalf("expected %q in\n%s", line, body)
+		}
+	}
+}
+
+func TestFormatLabels(t *testing.T) {
+	got := formatLabels([]label{{"topic", "a\"b\\c\nd"}})
+	if expected := `{topic="a\"b\\c\nd"}`; got != expected {
+		t.Fatalf("expected %s, got %s", expected, got)
+	}
+}
+
+func TestExpvarCollector(t *testing.T) {
+	c := NewExpvarCollector()
+	e := New(WithMetrics(c))
+	e.SubscribeSync("orders.created", func(id string) {})
+	e.Publish("orders.created", "o-1")
+
+	rec := httptest.NewRecorder()
+	c.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/eventbus", nil))
+	var s MetricsSnapshot
+	if err := json.Unmarshal(rec.Body.Bytes(), &s); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Topics) != 1 || s.Topics[0].Published != 1 {
+		t.Fatalf("unexpected topics %v", s.Topics)
+	}
+	if len(s.Subscribers) != 1 || s.Subscribers[0].Delivered != 1 || len(s.Subscribers[0].Latency.Counts) != len(DefaultBuckets) {
+		t.Fatalf("unexpected subscribers %v", s.Subscribers)
+	}
+}
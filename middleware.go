@@ -0,0 +1,161 @@
+package eventbus
+
+import (
+	"context"
+	"time"
+)
+
+// Delivery is an event on its way to a single subscriber.
+type Delivery struct {
+	// Topic is the published topic.
+	Topic string
+	// Pattern is the topic pattern the subscriber subscribed to.
+	Pattern string
+	// Subscriber is the handler function name, or the key given to SubscribeWith.
+	Subscriber string
+	Args       []interface{}
+}
+
+// Handler delivers d and returns the reply of the subscriber, the values
+// returned by its handler and the error it failed with.
+type Handler func(ctx context.Context, d *Delivery) ([]interface{}, error)
+
+// Middleware wraps the delivery to a subscriber. It may change the delivery,
+// reject it by returning an error without calling next, or inspect the result.
+type Middleware func(next Handler) Handler
+
+type topicMiddleware struct {
+	pattern    string
+	middleware []Middleware
+}
+
+// middlewares holds the middleware registered with the bus options.
+type middlewares struct {
+	global []Middleware
+	topics []topicMiddleware
+}
+
+// chain returns the middleware applying to a delivery of topic, outermost first.
+func (m *middlewares) chain(topic string, local []Middleware) []Middleware {
+	var chain []Middleware
+	chain = append(chain, m.global...)
+	for _, tm := range m.topics {
+		if matchTopic(tm.pattern, topic) {
+			chain = append(chain, tm.middleware...)
+		}
+	}
+	return append(chain, local...)
+}
+
+// invoker runs the deliveries to a subscription through its middleware chain.
+type invoker struct {
+	pattern    string
+	subscriber string
+	onError    ErrorHandler
+	global     *middlewares
+	local      []Middleware
+	retry      *RetryPolicy
+	metrics    MetricsCollector
+	tracer     Tracer
+	deadLetter func(i *invoker, ev *event, err error, attempts int)
+	// shutdown ends the backoff between retries, the delivery fails with its last error
+	shutdown <-chan struct{}
+}
+
+func (bus *EventBus) newInvoker(pattern, subscriber string, opts *subscribeOptions) *invoker {
+	inv := &invoker{
+		pattern:    pattern,
+		subscriber: subscriber,
+		onError:    bus.onError,
+		global:     bus.middlewares,
+		local:      opts.middleware,
+		retry:      opts.retry,
+		metrics:    bus.metrics,
+		tracer:     bus.tracer,
+		shutdown:   bus.shutdown,
+	}
+	if bus.deadLetters != nil {
+		inv.deadLetter = bus.deadLetter
+	}
+	return inv
+}
+
+// invoke passes ev through the middleware to next and reports a panic instead of propagating it.
+// A failed delivery is dead-lettered unless the error is replied to a requester.
+func (i *invoker) invoke(ctx context.Context, ev *event, next Handler) reply {
+	return i.deliver(ctx, ev, next, nil)
+}
+
+// invokeRetry is invoke retrying a failed delivery by the retry policy of the subscription.
+func (i *invoker) invokeRetry(ctx context.Context, ev *event, next Handler) reply {
+	return i.deliver(ctx, ev, next, i.retry)
+}
+
+func (i *invoker) deliver(ctx context.Context, ev *event, next Handler, retry *RetryPolicy) reply {
+	r := i.call(ctx, ev, next)
+	attempts := 1
+	for r.err != nil && retry.retries(attempts, r.err) && retry.wait(ctx, attempts, i.shutdown) {
+		attempts++
+		r = i.call(ctx, ev, next)
+	}
+	if r.err != nil && ev.reply == nil {
+		// nobody waits for the error, panics were reported by call already
+		if he, ok := r.err.(*HandlerError); !ok || he.Panic == nil {
+			i.onError(&HandlerError{
+				Topic:   i.pattern,
+				Handler: i.subscriber,
+				Err:     r.err,
+			})
+		}
+		if i.deadLetter != nil {
+			i.deadLetter(i, ev, r.err, attempts)
+		}
+	}
+	return r
+}
+
+// call makes a single delivery attempt.
+func (i *invoker) call(ctx context.Context, ev *event, next Handler) (r reply) {
+	d := &Delivery{
+		Topic:      ev.topic,
+		Pattern:    i.pattern,
+		Subscriber: i.subscriber,
+		Args:       ev.args,
+	}
+	ctx, span := i.tracer.StartDelivery(ctx, d)
+	var start time.Time
+	if i.metrics != nil {
+		start = time.Now()
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			err := newPanicError(i.pattern, i.subscriber, p)
+			i.onError(err)
+			r = reply{err: err}
+		}
+		if i.metrics != nil {
+			i.measure(ev, start, r.err)
+		}
+		span.End(r.err)
+	}()
+	chain := i.global.chain(ev.topic, i.local)
+	for j := len(chain) - 1; j >= 0; j-- {
+		next = chain[j](next)
+	}
+	r.values, r.err = next(ctx, d)
+	return r
+}
+
+func (i *invoker) measure(ev *event, start time.Time, err error) {
+	m := DeliveryMetrics{
+		Topic:      ev.topic,
+		Pattern:    i.pattern,
+		Subscriber: i.subscriber,
+		Duration:   time.Since(start),
+		Err:        err,
+	}
+	if !ev.at.IsZero() {
+		m.Latency = start.Sub(ev.at)
+	}
+	i.metrics.Delivered(m)
+}
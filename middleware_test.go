@@ -0,0 +1,130 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/danielhookx/fission"
+)
+
+func recordMiddleware(name string, calls *[]string) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, d *Delivery) ([]interface{}, error) {
+			*calls = append(*calls, name+" "+d.Topic)
+			return next(ctx, d)
+		}
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	var calls []string
+	e := New(
+		WithMiddleware(recordMiddleware("global", &calls)),
+		WithTopicMiddleware("orders.*", recordMiddleware("topic", &calls)),
+	)
+	e.SubscribeSync("orders.#", func(id string) {
+		calls = append(calls, "handler "+id)
+	}, WithSubscriptionMiddleware(recordMiddleware("subscription", &calls)))
+
+	e.Publish("orders.created", "1")
+	e.Publish("orders", "2")
+
+	expected := []string{
+		"global orders.created", "topic orders.created", "subscription orders.created", "handler 1",
+		"global orders", "subscription orders", "handler 2",
+	}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Fatalf("expected %v, got %v", expected, calls)
+	}
+}
+
+func TestMiddlewareDelivery(t *testing.T) {
+	var deliveries []Delivery
+	e := New(WithMiddleware(func(next Handler) Handler {
+		return func(ctx context.Context, d *Delivery) ([]interface{}, error) {
+			deliveries = append(deliveries, *d)
+			return next(ctx, d)
+		}
+	}))
+	e.SubscribeSync("orders.*", a)
+	e.SubscribeWith("orders.*", "mock", createMockDistHandlerFunc)
+	e.Publish("orders.created", "jack")
+
+	if len(deliveries) != 2 {
+		t.Fatalf("expected 2 deliveries, got %d", len(deliveries))
+	}
+	if !strings.HasSuffix(deliveries[0].Subscriber, ".a") || deliveries[1].Subscriber != "mock" {
+		t.Fatalf("unexpected subscribers %s, %s", deliveries[0].Subscriber, deliveries[1].Subscriber)
+	}
+	for _, d := range deliveries {
+		if d.Topic != "orders.created" || d.Pattern != "orders.*" || !reflect.DeepEqual(d.Args, []interface{}{"jack"}) {
+			t.Fatalf("unexpected delivery %+v", d)
+		}
+	}
+}
+
+func TestMiddlewareTransformAndReject(t *testing.T) {
+	e := New(WithMiddleware(func(next Handler) Handler {
+		return func(ctx context.Context, d *Delivery) ([]interface{}, error) {
+			if MetadataFromContext(ctx)["user"] == "" {
+				return nil, errors.New("unauthorized")
+			}
+			d.Args = []interface{}{strings.ToUpper(d.Args[0].(string))}
+			return next(ctx, d)
+		}
+	}))
+	var got []string
+	e.SubscribeSync("greet", func(name string) string {
+		got = append(got, name)
+		return "hello " + name
+	})
+
+	e.Publish("greet", "anonymous")
+	if len(got) != 0 {
+		t.Fatalf("rejected delivery reached the handler: %v", got)
+	}
+	if _, err := e.Request(context.Background(), "greet", "jack"); err == nil || err.Error() != "unauthorized" {
+		t.Fatalf("expected unauthorized, got %v", err)
+	}
+
+	ctx := ContextWithMetadata(context.Background(), Metadata{"user": "root"})
+	values, err := e.Request(ctx, "greet", "jack")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values[0] != "hello JACK" {
+		t.Fatalf("unexpected reply %v", values)
+	}
+}
+
+func TestMiddlewareResult(t *testing.T) {
+	var results []string
+	e := New(WithErrorHandler(nil), WithMiddleware(func(next Handler) Handler {
+		return func(ctx context.Context, d *Delivery) ([]interface{}, error) {
+			values, err := next(ctx, d)
+			results = append(results, fmt.Sprint(values, err != nil))
+			return values, err
+		}
+	}))
+	e.SubscribeSync("test", func(i int) (int, error) {
+		if i < 0 {
+			return 0, errors.New("negative")
+		}
+		return i * 2, nil
+	})
+	e.SubscribeWith("panic", "mock", func(key any) fission.Distribution {
+		return &panicDist{mockDist{key: key.(string)}}
+	})
+	e.Publish("test", 2)
+	e.Publish("test", -1)
+	e.Publish("panic", 1)
+
+	expected := []string{"[4] false", "[0] true"}
+	if !reflect.DeepEqual(results, expected) {
+		t.Fatalf("expected %v, got %v", expected, results)
+	}
+}
@@ -3,11 +3,15 @@ package eventbus
 import (
 	"context"
 	"encoding/gob"
+	"errors"
 	"fmt"
 	"log"
 	"net"
 	"net/rpc"
 	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/danielhookx/fission"
 )
@@ -15,6 +19,10 @@ import (
 type SubArgs struct {
 	RemoteURL string
 	Topic     string
+	// ID identifies the subscription of the peer, it is passed back on unsubscribe
+	ID uint64
+	// Filters are the field filters of the subscription, events not matching them are not sent
+	Filters []FieldFilter
 }
 
 type SubReply struct {
@@ -22,27 +30,67 @@ type SubReply struct {
 
 type UnsubArgs struct {
 	Topic string
+	ID    uint64
 }
 
 type UnsubReply struct {
 }
 
 type PubArgs struct {
-	Topic string
-	Data  any
+	Topic    string
+	Data     any
+	Deadline time.Time
+	Metadata Metadata
+	// Retain has the peer retain the event as well
+	Retain bool
+	// Trace is the trace context of the delivery forwarding the event
+	Trace Metadata
 }
 
 type PubReply struct{}
 
+type ReqArgs struct {
+	Topic    string
+	Data     any
+	Deadline time.Time
+	Metadata Metadata
+	// All requests the replies of every remote responder instead of the first one
+	All bool
+	// Trace is the trace context of the delivery forwarding the request
+	Trace Metadata
+}
+
+type ReqReply struct {
+	Replies [][]interface{}
+	Err     string
+}
+
 type RPCProxy struct {
 	rawURL    string
 	remoteURL string
 	bus       Eventbus
+	listener  net.Listener
+	retry     *RetryPolicy
+
+	lock  sync.Mutex
+	dists map[string]*netPublishDist
+
+	nextID     atomic.Uint64
+	remoteLock sync.Mutex
+	// remote holds the subscriptions made at the peer by id
+	remote map[uint64]*remoteSubscription
 }
 
-func NewRPCProxyCreator(rawURL, remoteURL string) ProxyCreator {
+// remoteSubscription is a subscription made at the peer for a local one.
+type remoteSubscription struct {
+	topic string
+	// sub is the local subscription, nil until it is made
+	sub Subscription
+}
+
+func NewRPCProxyCreator(rawURL, remoteURL string, opt ...RPCProxyOption) ProxyCreator {
 	return func(bus Eventbus) Eventbus {
-		b, err := NewRPCProxy(rawURL, remoteURL, bus)
+		b, err := NewRPCProxy(rawURL, remoteURL, bus, opt...)
 		if err != nil {
 			panic(err)
 		}
@@ -50,16 +98,26 @@ func NewRPCProxyCreator(rawURL, remoteURL string) ProxyCreator {
 	}
 }
 
-func NewRPCProxy(rawURL, remoteURL string, bus Eventbus) (*RPCProxy, error) {
+func NewRPCProxy(rawURL, remoteURL string, bus Eventbus, opt ...RPCProxyOption) (*RPCProxy, error) {
+	opts := rpcProxyOptions{}
+	for _, o := range opt {
+		o.apply(&opts)
+	}
 	p := &RPCProxy{
 		rawURL:    rawURL,
 		remoteURL: remoteURL,
 		bus:       bus,
+		retry:     opts.retry,
+		dists:     make(map[string]*netPublishDist),
+		remote:    make(map[uint64]*remoteSubscription),
 	}
 	gob.Register([]interface{}{})
-	rpc.Register(p)
-	// Registers an HTTP handler for RPC messages
-	// rpc.HandleHTTP()
+	gob.Register(DeadLetter{})
+	// Every proxy has its own server so that it can be closed independently
+	server := rpc.NewServer()
+	if err := server.Register(p); err != nil {
+		return nil, err
+	}
 
 	u, err := url.Parse(rawURL)
 	if err != nil {
@@ -70,132 +128,412 @@ func NewRPCProxy(rawURL, remoteURL string, bus Eventbus) (*RPCProxy, error) {
 	if err != nil {
 		return nil, err
 	}
-	// start http server goroutine
-	// go http.Serve(listener, nil)
+	p.listener = listener
 	go func() {
 		for {
 			conn, err := listener.Accept()
 			if err != nil {
+				if errors.Is(err, net.ErrClosed) {
+					return
+				}
 				log.Fatal("Accept error:", err)
 			}
-			go rpc.ServeConn(conn)
+			go server.ServeConn(conn)
 		}
 	}()
 	return p, nil
 }
 
-func (p *RPCProxy) Subscribe(topic string, fn interface{}) error {
+func (p *RPCProxy) Subscribe(topic string, fn interface{}, opt ...SubscribeOption) (Subscription, error) {
 	// Call the remote subscription method to register the event to the remote endpoint.
-	remote, err := url.Parse(p.remoteURL)
+	id, err := p.remoteSubscribe("RPCProxy.RPCSubscribe", topic, opt)
 	if err != nil {
-		return fmt.Errorf("Parse remote url error: %w", err)
+		return nil, err
 	}
-	client, err := rpc.Dial(remote.Scheme, parseAddress(remote))
+	// Call the local subscription method and register the callback locally
+	sub, err := p.bus.Subscribe(topic, fn, opt...)
+	return p.wrap(id, sub, err)
+}
+
+func (p *RPCProxy) SubscribeSync(topic string, fn interface{}, opt ...SubscribeOption) (Subscription, error) {
+	// Call the remote subscription method to register the event to the remote endpoint.
+	id, err := p.remoteSubscribe("RPCProxy.RPCSubscribeSync", topic, opt)
 	if err != nil {
-		return fmt.Errorf("Client connection error %w", err)
+		return nil, err
 	}
+	// Call the local subscription method and register the callback locally
+	sub, err := p.bus.SubscribeSync(topic, fn, opt...)
+	return p.wrap(id, sub, err)
+}
 
-	reply := SubReply{}
-	err = client.Call("RPCProxy.RPCSubscribe", &SubArgs{
-		RemoteURL: p.rawURL,
-		Topic:     topic,
-	}, &reply)
+func (p *RPCProxy) SubscribeOrdered(topic string, fn interface{}, opt ...SubscribeOption) (Subscription, error) {
+	// Remote events are published one by one, the local subscription keeps them in order.
+	id, err := p.remoteSubscribe("RPCProxy.RPCSubscribe", topic, opt)
 	if err != nil {
-		return fmt.Errorf("Client invocation error: %w", err)
+		return nil, err
 	}
-	// Call the local subscription method and register the callback locally
-	return p.bus.Subscribe(topic, fn)
+	sub, err := p.bus.SubscribeOrdered(topic, fn, opt...)
+	return p.wrap(id, sub, err)
 }
 
-func (p *RPCProxy) SubscribeSync(topic string, fn interface{}) error {
-	// Call the remote subscription method to register the event to the remote endpoint.
-	remote, err := url.Parse(p.remoteURL)
+func (p *RPCProxy) SubscribeOnce(topic string, fn interface{}, opt ...SubscribeOption) (Subscription, error) {
+	id, err := p.remoteSubscribe("RPCProxy.RPCSubscribe", topic, opt)
 	if err != nil {
-		return fmt.Errorf("Parse remote url error: %w", err)
+		return nil, err
 	}
-	client, err := rpc.Dial(remote.Scheme, parseAddress(remote))
+	sub, err := p.bus.SubscribeOnce(topic, fn, append(opt, p.removeOnInactive(id))...)
+	return p.wrap(id, sub, err)
+}
+
+func (p *RPCProxy) SubscribeOnceSync(topic string, fn interface{}, opt ...SubscribeOption) (Subscription, error) {
+	id, err := p.remoteSubscribe("RPCProxy.RPCSubscribeSync", topic, opt)
 	if err != nil {
-		return fmt.Errorf("Client connection error %w", err)
+		return nil, err
 	}
+	sub, err := p.bus.SubscribeOnceSync(topic, fn, append(opt, p.removeOnInactive(id))...)
+	return p.wrap(id, sub, err)
+}
 
-	reply := &SubReply{}
-	err = client.Call("RPCProxy.RPCSubscribeSync", &SubArgs{
-		RemoteURL: p.rawURL,
-		Topic:     topic,
-	}, &reply)
+// SubscribeWithReplay replays the events buffered by the local bus, which include
+// the remote events it received while buffering the topic.
+func (p *RPCProxy) SubscribeWithReplay(topic string, fn interface{}, replay Replay, opt ...SubscribeOption) (Subscription, error) {
+	id, err := p.remoteSubscribe("RPCProxy.RPCSubscribe", topic, opt)
 	if err != nil {
-		return fmt.Errorf("Client invocation error: %w", err)
+		return nil, err
 	}
-	// Call the local subscription method and register the callback locally
-	return p.bus.SubscribeSync(topic, fn)
+	sub, err := p.bus.SubscribeWithReplay(topic, fn, replay, opt...)
+	return p.wrap(id, sub, err)
+}
+
+func (p *RPCProxy) SubscribeBatch(topic string, fn interface{}, size int, window time.Duration, opt ...SubscribeOption) (Subscription, error) {
+	id, err := p.remoteSubscribe("RPCProxy.RPCSubscribe", topic, opt)
+	if err != nil {
+		return nil, err
+	}
+	sub, err := p.bus.SubscribeBatch(topic, fn, size, window, opt...)
+	return p.wrap(id, sub, err)
+}
+
+func (p *RPCProxy) WaitFor(ctx context.Context, topic string) ([]interface{}, error) {
+	id, err := p.remoteSubscribe("RPCProxy.RPCSubscribe", topic, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer p.remoteUnsubscribe(id)
+	return p.bus.WaitFor(ctx, topic)
 }
 
-func (p *RPCProxy) SubscribeWith(topic string, key any, distHandler fission.CreateDistributionHandleFunc) error {
-	return p.bus.SubscribeWith(topic, key, distHandler)
+func (p *RPCProxy) SubscribeWith(topic string, key any, distHandler fission.CreateDistributionHandleFunc, opt ...SubscribeOption) (Subscription, error) {
+	return p.bus.SubscribeWith(topic, key, distHandler, opt...)
 }
 
 func (p *RPCProxy) Unsubscribe(topic string, handler interface{}) error {
-	// Call the remote unsubscription method to remove the event.
-	remote, err := url.Parse(p.remoteURL)
-	if err != nil {
-		return fmt.Errorf("Parse remote url error: %w", err)
+	// Call the local unsubscription method and remove the callback locally
+	if err := p.bus.Unsubscribe(topic, handler); err != nil {
+		return err
+	}
+	// Remove the remote subscriptions of the removed callbacks, the others keep receiving the topic
+	var errs []error
+	for _, id := range p.inactive(topic) {
+		errs = append(errs, p.remoteUnsubscribe(id))
 	}
-	client, err := rpc.Dial(remote.Scheme, parseAddress(remote))
+	return errors.Join(errs...)
+}
+
+// remoteSubscribe subscribes at the peer and returns the id of the remote subscription.
+func (p *RPCProxy) remoteSubscribe(serviceMethod string, topic string, opt []SubscribeOption) (uint64, error) {
+	id := p.nextID.Add(1)
+	err := callRemote(context.Background(), p.remoteURL, serviceMethod, &SubArgs{
+		RemoteURL: p.rawURL,
+		Topic:     topic,
+		ID:        id,
+		Filters:   newSubscribeOptions(opt).fieldFilters,
+	}, &SubReply{})
 	if err != nil {
-		return fmt.Errorf("Client connection error %w", err)
+		return 0, err
+	}
+	p.remoteLock.Lock()
+	p.remote[id] = &remoteSubscription{topic: topic}
+	p.remoteLock.Unlock()
+	return id, nil
+}
+
+// remoteUnsubscribe removes the remote subscription id, once.
+func (p *RPCProxy) remoteUnsubscribe(id uint64) error {
+	p.remoteLock.Lock()
+	rs, ok := p.remote[id]
+	delete(p.remote, id)
+	p.remoteLock.Unlock()
+	if !ok {
+		return nil
 	}
+	return callRemote(context.Background(), p.remoteURL, "RPCProxy.RPCUnsubscribe", &UnsubArgs{
+		Topic: rs.topic,
+		ID:    id,
+	}, &UnsubReply{})
+}
+
+// removeOnInactive returns a SubscribeOption removing the remote subscription id
+// once the local subscription delivered its only event.
+func (p *RPCProxy) removeOnInactive(id uint64) SubscribeOption {
+	return withInactive(func() {
+		// the subscription is deactivated while a remote event is delivered
+		go p.remoteUnsubscribe(id)
+	})
+}
+
+// inactive returns the ids of the remote subscriptions to topic whose local subscription is gone.
+func (p *RPCProxy) inactive(topic string) []uint64 {
+	p.remoteLock.Lock()
+	defer p.remoteLock.Unlock()
+	var ids []uint64
+	for id, rs := range p.remote {
+		if rs.topic == topic && rs.sub != nil && !rs.sub.Active() {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
 
-	reply := &UnsubReply{}
-	err = client.Call("RPCProxy.RPCUnsubscribe", &UnsubArgs{
-		Topic: topic,
-	}, &reply)
+// wrap ties the local subscription to the remote subscription id,
+// which is removed again when the local one failed.
+func (p *RPCProxy) wrap(id uint64, sub Subscription, err error) (Subscription, error) {
 	if err != nil {
-		return fmt.Errorf("Client invocation error: %w", err)
+		p.remoteUnsubscribe(id)
+		return nil, err
 	}
-	// Call the local unsubscription method and remove the callback locally
-	return p.bus.Unsubscribe(topic, handler)
+	p.remoteLock.Lock()
+	if rs, ok := p.remote[id]; ok {
+		rs.sub = sub
+	}
+	p.remoteLock.Unlock()
+	return &proxySubscription{
+		Subscription: sub,
+		proxy:        p,
+		id:           id,
+	}, nil
 }
 
 func (p *RPCProxy) Publish(topic string, args ...interface{}) {
 	p.bus.Publish(topic, args...)
 }
 
+func (p *RPCProxy) PublishE(topic string, args ...interface{}) error {
+	return p.bus.PublishE(topic, args...)
+}
+
+func (p *RPCProxy) PublishContext(ctx context.Context, topic string, args ...interface{}) error {
+	return p.bus.PublishContext(ctx, topic, args...)
+}
+
+func (p *RPCProxy) PublishAfter(d time.Duration, topic string, args ...interface{}) (Scheduled, error) {
+	return p.bus.PublishAfter(d, topic, args...)
+}
+
+func (p *RPCProxy) PublishAt(t time.Time, topic string, args ...interface{}) (Scheduled, error) {
+	return p.bus.PublishAt(t, topic, args...)
+}
+
+func (p *RPCProxy) PublishRetained(topic string, args ...interface{}) error {
+	return p.bus.PublishRetained(topic, args...)
+}
+
+func (p *RPCProxy) Retained(topic string) ([]interface{}, bool) {
+	return p.bus.Retained(topic)
+}
+
+func (p *RPCProxy) ClearRetained(topic string) {
+	p.bus.ClearRetained(topic)
+}
+
+func (p *RPCProxy) Request(ctx context.Context, topic string, args ...interface{}) ([]interface{}, error) {
+	return p.bus.Request(ctx, topic, args...)
+}
+
+func (p *RPCProxy) RequestAll(ctx context.Context, topic string, args ...interface{}) ([][]interface{}, error) {
+	return p.bus.RequestAll(ctx, topic, args...)
+}
+
+func (p *RPCProxy) QueueStats(topic string) []QueueStats {
+	return p.bus.QueueStats(topic)
+}
+
+func (p *RPCProxy) Topics() []string {
+	return p.bus.Topics()
+}
+
+func (p *RPCProxy) Subscribers(topic string) []SubscriberInfo {
+	return p.bus.Subscribers(topic)
+}
+
+func (p *RPCProxy) HasSubscribers(topic string) bool {
+	return p.bus.HasSubscribers(topic)
+}
+
+func (p *RPCProxy) Drain(ctx context.Context) error {
+	return p.bus.Drain(ctx)
+}
+
+// Close removes the subscriptions of the proxy at the peer, stops accepting
+// remote calls and closes the wrapped bus.
+func (p *RPCProxy) Close(ctx context.Context) error {
+	p.remoteLock.Lock()
+	ids := make([]uint64, 0, len(p.remote))
+	for id := range p.remote {
+		ids = append(ids, id)
+	}
+	p.remoteLock.Unlock()
+	var errs []error
+	for _, id := range ids {
+		// best effort, the peer may be gone already
+		errs = append(errs, p.remoteUnsubscribe(id))
+	}
+	err := p.listener.Close()
+	if errors.Is(err, net.ErrClosed) {
+		err = nil
+	}
+	return errors.Join(append(errs, err, p.bus.Close(ctx))...)
+}
+
 func (p *RPCProxy) RPCSubscribe(args *SubArgs, reply *SubReply) error {
 	// Receive subscription method calls from the peer
 	// callback method actually executes the remote call of Publish
-	return p.bus.SubscribeWith(args.Topic, args.Topic, createNetPublishDist(args))
+	return p.forward(args)
 }
 
 func (p *RPCProxy) RPCSubscribeSync(args *SubArgs, reply *SubReply) error {
 	// Receive subscription method calls from the peer
 	// callback method actually executes the remote call of Publish
-	p.bus.SubscribeWith(args.Topic, args.Topic, createNetPublishDist(args))
-	return nil
+	return p.forward(args)
+}
+
+// forward sends the events of a topic to the peer. All subscriptions of the peer
+// to the topic share one distribution, which sends the events matching the
+// filters of at least one of them.
+func (p *RPCProxy) forward(args *SubArgs) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	d, ok := p.dists[args.Topic]
+	if !ok {
+		d = newNetPublishDist(args.Topic, args, p.retry, tracerOf(p.bus), shutdownOf(p.bus))
+		p.dists[args.Topic] = d
+	}
+	// the filters have to be in place before the retained events are delivered on subscribe
+	d.filters.add(args.ID, args.Filters)
+	_, err := p.bus.SubscribeWith(args.Topic, args.Topic, func(key any) fission.Distribution {
+		return d
+	})
+	if err != nil {
+		p.unforward(args.Topic, args.ID)
+	}
+	return err
+}
+
+// unforward removes the subscription id of the peer and stops sending the topic
+// once it was the last one, the lock has to be held. The distribution is kept,
+// the bus hands the same one out again when the peer subscribes to the topic.
+func (p *RPCProxy) unforward(topic string, id uint64) {
+	d, ok := p.dists[topic]
+	if !ok || d.filters.remove(id) > 0 {
+		return
+	}
+	p.bus.Unsubscribe(topic, topic)
 }
 
 func (p *RPCProxy) RPCUnsubscribe(args *UnsubArgs, reply *UnsubReply) error {
-	p.bus.Unsubscribe(args.Topic, args.Topic)
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.unforward(args.Topic, args.ID)
 	return nil
 }
 
 func (p *RPCProxy) RPCPublish(args *PubArgs, reply *PubReply) error {
+	ctx, cancel := remoteContext(args.Deadline, args.Metadata)
+	defer cancel()
+	ctx = tracerOf(p.bus).Extract(ctx, args.Trace)
+	if args.Retain {
+		ctx = withRetained(ctx)
+	}
 	params := args.Data.([]interface{})
-	p.bus.Publish(args.Topic, params...)
-	return nil
+	return p.bus.PublishContext(ctx, args.Topic, params...)
 }
 
-func createNetPublishDist(args *SubArgs) fission.CreateDistributionHandleFunc {
-	return func(key any) fission.Distribution {
-		return &netPublishDist{
-			key:  key,
-			args: args,
+// RPCRequest answers a request of the peer with the replies of the local responders.
+// The errors of failed replies are returned in the reply, they do not fail the call.
+func (p *RPCProxy) RPCRequest(args *ReqArgs, reply *ReqReply) error {
+	ctx, cancel := remoteContext(args.Deadline, args.Metadata)
+	defer cancel()
+	ctx = tracerOf(p.bus).Extract(ctx, args.Trace)
+	params := args.Data.([]interface{})
+	var err error
+	if args.All {
+		reply.Replies, err = p.bus.RequestAll(ctx, args.Topic, params...)
+	} else {
+		var values []interface{}
+		if values, err = p.bus.Request(ctx, args.Topic, params...); err == nil {
+			reply.Replies = [][]interface{}{values}
 		}
 	}
+	if err != nil && !errors.Is(err, ErrNoResponders) {
+		reply.Err = err.Error()
+	}
+	return nil
+}
+
+// remoteContext rebuilds the context of the remote caller from its deadline and metadata.
+func remoteContext(deadline time.Time, md Metadata) (context.Context, context.CancelFunc) {
+	ctx := context.Background()
+	if md != nil {
+		ctx = ContextWithMetadata(ctx, md)
+	}
+	if deadline.IsZero() {
+		return ctx, func() {}
+	}
+	return context.WithDeadline(ctx, deadline)
+}
+
+func (p *RPCProxy) busTracer() Tracer {
+	return tracerOf(p.bus)
+}
+
+func (p *RPCProxy) busShutdown() <-chan struct{} {
+	return shutdownOf(p.bus)
+}
+
+// proxySubscription also removes the subscription from the remote endpoint.
+type proxySubscription struct {
+	Subscription
+	proxy *RPCProxy
+	id    uint64
+}
+
+func (s *proxySubscription) Unsubscribe() error {
+	if !s.Active() {
+		return nil
+	}
+	// the local handler is removed even when the peer cannot be reached
+	return errors.Join(s.proxy.remoteUnsubscribe(s.id), s.Subscription.Unsubscribe())
 }
 
 type netPublishDist struct {
-	key  any
-	args *SubArgs
+	key     any
+	args    *SubArgs
+	filters *remoteFilters
+	retry   *RetryPolicy
+	tracer  Tracer
+	// shutdown ends the backoff between retried publishes when the bus is drained
+	shutdown <-chan struct{}
+}
+
+func newNetPublishDist(key any, args *SubArgs, retry *RetryPolicy, tracer Tracer, shutdown <-chan struct{}) *netPublishDist {
+	return &netPublishDist{
+		key:      key,
+		args:     args,
+		filters:  newRemoteFilters(),
+		retry:    retry,
+		tracer:   tracer,
+		shutdown: shutdown,
+	}
 }
 
 func (d *netPublishDist) Register(ctx context.Context) {
@@ -207,23 +545,65 @@ func (d *netPublishDist) Key() any {
 }
 
 func (d *netPublishDist) Dist(data any) error {
-	remote, err := url.Parse(d.args.RemoteURL)
-	if err != nil {
-		return fmt.Errorf("Parse remote url error: %w", err)
+	return d.publish(context.Background(), d.args.Topic, data)
+}
+
+// distEvent forwards the published topic, which differs from the subscribed one for wildcard subscriptions.
+func (d *netPublishDist) distEvent(ctx context.Context, topic string, args []interface{}) error {
+	if !d.filters.match(args) {
+		return nil
 	}
-	client, err := rpc.Dial(remote.Scheme, parseAddress(remote))
-	if err != nil {
-		return fmt.Errorf("Client connection error %w", err)
+	return d.publish(ctx, topic, args)
+}
+
+func (d *netPublishDist) publish(ctx context.Context, topic string, data any) error {
+	args := &PubArgs{
+		Topic:    topic,
+		Data:     data,
+		Metadata: MetadataFromContext(ctx),
+		Retain:   isRetained(ctx),
+		Trace:    injectTrace(d.tracer, ctx),
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		args.Deadline = deadline
+	}
+	err := callRemote(ctx, d.args.RemoteURL, "RPCProxy.RPCPublish", args, &PubReply{})
+	for attempts := 1; err != nil && d.retry.retries(attempts, err) && d.retry.wait(ctx, attempts, d.shutdown); attempts++ {
+		err = callRemote(ctx, d.args.RemoteURL, "RPCProxy.RPCPublish", args, &PubReply{})
 	}
+	return err
+}
 
-	reply := &PubReply{}
-	err = client.Call("RPCProxy.RPCPublish", &PubArgs{
-		Topic: d.args.Topic,
-		Data:  data,
-	}, &reply)
-	if err != nil {
-		return fmt.Errorf("Client invocation error: %w", err)
+// distRequest forwards a request to the peer and hands its replies over to the requester.
+// A request for the first reply only asks the peer for its first reply.
+func (d *netPublishDist) distRequest(ctx context.Context, topic string, data []interface{}, replies *replies) error {
+	if !d.filters.match(data) {
+		return nil
+	}
+	args := &ReqArgs{
+		Topic:    topic,
+		Data:     data,
+		Metadata: MetadataFromContext(ctx),
+		All:      !replies.first,
+		Trace:    injectTrace(d.tracer, ctx),
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		args.Deadline = deadline
+	}
+	replies.expect()
+	var rep ReqReply
+	if err := callRemote(ctx, d.args.RemoteURL, "RPCProxy.RPCRequest", args, &rep); err != nil {
+		replies.send(reply{err: err})
+		return nil
+	}
+	received := make([]reply, 0, len(rep.Replies)+1)
+	for _, values := range rep.Replies {
+		received = append(received, reply{values: values})
+	}
+	if rep.Err != "" {
+		received = append(received, reply{err: errors.New(rep.Err)})
 	}
+	replies.send(received...)
 	return nil
 }
 
@@ -231,6 +611,31 @@ func (d *netPublishDist) Close() error {
 	return nil
 }
 
+func callRemote(ctx context.Context, rawURL string, serviceMethod string, args any, reply any) error {
+	remote, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("Parse remote url error: %w", err)
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, remote.Scheme, parseAddress(remote))
+	if err != nil {
+		return fmt.Errorf("Client connection error %w", err)
+	}
+	client := rpc.NewClient(conn)
+	defer client.Close()
+
+	call := client.Go(serviceMethod, args, reply, make(chan *rpc.Call, 1))
+	select {
+	case <-call.Done:
+		if call.Error != nil {
+			return fmt.Errorf("Client invocation error: %w", call.Error)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("Client invocation error: %w", ctx.Err())
+	}
+}
+
 func parseAddress(u *url.URL) string {
 	if u.Scheme == "unix" {
 		return u.Path
@@ -0,0 +1,334 @@
+package eventbus
+
+import (
+	"context"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestProxyPair(t *testing.T) (Eventbus, Eventbus) {
+	dir := t.TempDir()
+	a := "unix://" + filepath.Join(dir, "a.sock")
+	b := "unix://" + filepath.Join(dir, "b.sock")
+	pub, err := NewRPCProxy(a, b, New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub, err := NewRPCProxy(b, a, New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		pub.Close(context.Background())
+		sub.Close(context.Background())
+	})
+	return pub, sub
+}
+
+func TestRPCProxyPublish(t *testing.T) {
+	pub, sub := newTestProxyPair(t)
+	got := make(chan string, 1)
+	if _, err := sub.Subscribe("orders.*", func(ctx context.Context, name string) {
+		got <- MetadataFromContext(ctx)["trace-id"] + ":" + name
+	}); err != nil {
+		t.Fatal(err)
+	}
+	ctx := ContextWithMetadata(context.Background(), Metadata{"trace-id": "1"})
+	if err := pub.PublishContext(ctx, "orders.created", "jack"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case v := <-got:
+		if v != "1:jack" {
+			t.Fatalf("unexpected delivery %s", v)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("remote subscriber was not called")
+	}
+}
+
+func TestRPCProxyUnsubscribe(t *testing.T) {
+	pub, sub := newTestProxyPair(t)
+	s, err := sub.SubscribeSync("test", func(name string) {
+		t.Error("unsubscribed handler should not be called")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Unsubscribe(); err != nil {
+		t.Fatal(err)
+	}
+	if err := pub.PublishE("test", "jack"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRPCProxyUnsubscribeOne(t *testing.T) {
+	pub, sub := newTestProxyPair(t)
+	s1, err := sub.SubscribeSync("test", func(name string) {
+		t.Error("unsubscribed handler should not be called")
+	}, WithFieldFilter(FieldFilter{Arg: 0, Value: "jack"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(chan string, 2)
+	if _, err := sub.SubscribeSync("test", func(name string) {
+		got <- name
+	}, WithFieldFilter(FieldFilter{Arg: 0, Value: "rose"})); err != nil {
+		t.Fatal(err)
+	}
+	if err := s1.Unsubscribe(); err != nil {
+		t.Fatal(err)
+	}
+	pub.Publish("test", "jack")
+	pub.Publish("test", "rose")
+	select {
+	case name := <-got:
+		if name != "rose" {
+			t.Fatalf("unexpected delivery %s", name)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the remaining subscription stopped receiving")
+	}
+	// the filters of the removed subscription are gone, so jack is not forwarded anymore
+	if pub.(*RPCProxy).dists["test"].filters.match([]interface{}{"jack"}) {
+		t.Fatal("expected the filters of the removed subscription to be gone")
+	}
+}
+
+// forwarding waits up to a second for the peer p to start or stop forwarding topic.
+func forwarding(p Eventbus, topic string, expected bool) bool {
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if p.HasSubscribers(topic) == expected {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRPCProxyUnsubscribeUnreachable(t *testing.T) {
+	pub, sub := newTestProxyPair(t)
+	s, err := sub.SubscribeSync("test", func(name string) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub.Close(context.Background())
+	if err := s.Unsubscribe(); err == nil {
+		t.Fatal("expected the error of the unreachable peer")
+	}
+	if s.Active() {
+		t.Fatal("expected the local subscription to be removed")
+	}
+}
+
+func TestRPCProxyCloseUnforwards(t *testing.T) {
+	pub, sub := newTestProxyPair(t)
+	if _, err := sub.SubscribeSync("test", func(name string) {}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sub.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if pub.HasSubscribers("test") {
+		t.Fatal("expected the peer to stop forwarding to the closed proxy")
+	}
+}
+
+func TestRPCProxyResubscribe(t *testing.T) {
+	pub, sub := newTestProxyPair(t)
+	s, err := sub.SubscribeSync("test", func(name string) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Unsubscribe(); err != nil {
+		t.Fatal(err)
+	}
+	got := make(chan string, 1)
+	if _, err := sub.SubscribeSync("test", func(name string) {
+		got <- name
+	}); err != nil {
+		t.Fatal(err)
+	}
+	pub.Publish("test", "jack")
+	select {
+	case <-got:
+	case <-time.After(time.Second):
+		t.Fatal("the topic was not forwarded again after resubscribing")
+	}
+}
+
+func TestRPCProxyOnceUnforwards(t *testing.T) {
+	pub, sub := newTestProxyPair(t)
+	got := make(chan string, 1)
+	if _, err := sub.SubscribeOnceSync("test", func(name string) {
+		got <- name
+	}); err != nil {
+		t.Fatal(err)
+	}
+	pub.Publish("test", "jack")
+	select {
+	case <-got:
+	case <-time.After(time.Second):
+		t.Fatal("once subscriber was not called")
+	}
+	if !forwarding(pub, "test", false) {
+		t.Fatal("expected the peer to stop forwarding after the only delivery")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		// WaitFor subscribes locally after the peer forwards, publish until it returned
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(10 * time.Millisecond):
+				pub.Publish("wait", "rose")
+			}
+		}
+	}()
+	_, err := sub.WaitFor(ctx, "wait")
+	close(done)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !forwarding(pub, "wait", false) {
+		t.Fatal("expected the peer to stop forwarding after WaitFor returned")
+	}
+}
+
+func TestRPCProxyRequest(t *testing.T) {
+	pub, sub := newTestProxyPair(t)
+	if _, err := sub.Subscribe("calculator", func(a, b int) int {
+		return a + b
+	}); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	values, err := pub.Request(ctx, "calculator", 10, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 1 || values[0] != 30 {
+		t.Fatalf("unexpected reply %v", values)
+	}
+
+	replies, err := pub.RequestAll(ctx, "calculator", 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(replies) != 1 || replies[0][0] != 3 {
+		t.Fatalf("unexpected replies %v", replies)
+	}
+}
+
+func TestRPCProxyFieldFilter(t *testing.T) {
+	pub, sub := newTestProxyPair(t)
+	got := make(chan string, 10)
+	if _, err := sub.SubscribeSync("orders", func(tenant string, id string) {
+		got <- id
+	}, WithFieldFilter(FieldFilter{Arg: 0, Value: "acme"})); err != nil {
+		t.Fatal(err)
+	}
+	// counts the events arriving over the network, including those the local filter drops
+	var received atomic.Int64
+	if _, err := sub.(*RPCProxy).bus.SubscribeSync("orders", func(tenant string, id string) {
+		received.Add(1)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for _, tenant := range []string{"other", "acme", "other"} {
+		if err := pub.PublishE("orders", tenant, tenant+"-1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	select {
+	case id := <-got:
+		if id != "acme-1" {
+			t.Fatalf("unexpected delivery %s", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("remote subscriber was not called")
+	}
+	if n := received.Load(); n != 1 {
+		t.Fatalf("expected only the matching event to be sent, %d were", n)
+	}
+}
+
+func TestRPCProxyPublishRetryStopsOnDrain(t *testing.T) {
+	dir := t.TempDir()
+	a := "unix://" + filepath.Join(dir, "a.sock")
+	b := "unix://" + filepath.Join(dir, "b.sock")
+	pub, err := NewRPCProxy(a, b, New(WithErrorHandler(nil)), WithPublishRetry(RetryPolicy{MaxAttempts: 10, Backoff: 300 * time.Millisecond}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { pub.Close(context.Background()) })
+	// the peer never listens, so every publish to it fails
+	if err := pub.RPCSubscribe(&SubArgs{RemoteURL: b, Topic: "orders.created"}, &SubReply{}); err != nil {
+		t.Fatal(err)
+	}
+	published := make(chan error, 1)
+	go func() {
+		published <- pub.PublishE("orders.created", "jack")
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	start := time.Now()
+	if err := pub.Drain(ctx); err != nil {
+		t.Fatalf("expected the backoff to end on Drain, got %v", err)
+	}
+	if d := time.Since(start); d > 500*time.Millisecond {
+		t.Fatalf("expected Drain to end the backoff, took %v", d)
+	}
+	<-published
+}
+
+func TestRPCProxyPublishRetry(t *testing.T) {
+	dir := t.TempDir()
+	a := "unix://" + filepath.Join(dir, "a.sock")
+	b := "unix://" + filepath.Join(dir, "b.sock")
+	pub, err := NewRPCProxy(a, b, New(), WithPublishRetry(RetryPolicy{MaxAttempts: 20, Backoff: 10 * time.Millisecond}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { pub.Close(context.Background()) })
+	// the peer subscribes before it listens, so that the first publishes fail to dial
+	if err := pub.RPCSubscribe(&SubArgs{RemoteURL: b, Topic: "orders.created"}, &SubReply{}); err != nil {
+		t.Fatal(err)
+	}
+	published := make(chan error, 1)
+	go func() {
+		published <- pub.PublishE("orders.created", "jack")
+	}()
+	time.Sleep(30 * time.Millisecond)
+
+	bus := New()
+	got := make(chan string, 1)
+	bus.Subscribe("orders.created", func(name string) {
+		got <- name
+	})
+	sub, err := NewRPCProxy(b, a, bus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sub.Close(context.Background()) })
+	if err := <-published; err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case v := <-got:
+		if v != "jack" {
+			t.Fatalf("unexpected delivery %s", v)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("remote publish was not retried")
+	}
+}
@@ -1,10 +1,22 @@
 package eventbus
 
+import "time"
+
 type ProxyCreator func(bus Eventbus) Eventbus
 
 type (
 	eventbusOptions struct {
-		proxyCreators []ProxyCreator
+		proxyCreators   []ProxyCreator
+		errorHandler    ErrorHandler
+		pool            *WorkerPool
+		middleware      []Middleware
+		topicMiddleware []topicMiddleware
+		retain          []string
+		replay          []replayConfig
+		schedulePolicy  SchedulePolicy
+		deadLetters     *deadLetters
+		metrics         MetricsCollector
+		tracer          Tracer
 	}
 
 	EventbusOption interface {
@@ -32,3 +44,281 @@ func WithProxys(proxyCreators ...ProxyCreator) EventbusOption {
 		o.proxyCreators = proxyCreators
 	})
 }
+
+// WithErrorHandler returns a EventbusOption that sets the handler receiving
+// recovered subscriber panics and delivery errors. A nil handler discards them.
+func WithErrorHandler(handler ErrorHandler) EventbusOption {
+	if handler == nil {
+		handler = func(err error) {}
+	}
+	return newFuncEventbusOption(func(o *eventbusOptions) {
+		o.errorHandler = handler
+	})
+}
+
+// WithWorkerPool returns a EventbusOption that runs asynchronous deliveries
+// on the given WorkerPool instead of a goroutine per event.
+func WithWorkerPool(pool *WorkerPool) EventbusOption {
+	return newFuncEventbusOption(func(o *eventbusOptions) {
+		o.pool = pool
+	})
+}
+
+// WithMiddleware returns a EventbusOption that wraps every delivery in mw,
+// the first middleware being the outermost one.
+func WithMiddleware(mw ...Middleware) EventbusOption {
+	return newFuncEventbusOption(func(o *eventbusOptions) {
+		o.middleware = append(o.middleware, mw...)
+	})
+}
+
+// WithTopicMiddleware returns a EventbusOption that wraps the deliveries of the
+// topics matching pattern in mw. It runs inside the middleware set by WithMiddleware.
+func WithTopicMiddleware(pattern string, mw ...Middleware) EventbusOption {
+	return newFuncEventbusOption(func(o *eventbusOptions) {
+		o.topicMiddleware = append(o.topicMiddleware, topicMiddleware{
+			pattern:    pattern,
+			middleware: mw,
+		})
+	})
+}
+
+// WithRetain returns a EventbusOption that retains the last event published to
+// the topics matching patterns, like PublishRetained does for a single publish.
+func WithRetain(patterns ...string) EventbusOption {
+	return newFuncEventbusOption(func(o *eventbusOptions) {
+		o.retain = append(o.retain, patterns...)
+	})
+}
+
+// WithReplayBuffer returns a EventbusOption that keeps the last size events of
+// each topic matching one of patterns for SubscribeWithReplay.
+func WithReplayBuffer(size int, patterns ...string) EventbusOption {
+	return newFuncEventbusOption(func(o *eventbusOptions) {
+		for _, p := range patterns {
+			o.replay = append(o.replay, replayConfig{pattern: p, size: size})
+		}
+	})
+}
+
+// WithSchedulePolicy returns a EventbusOption that sets what happens to the events
+// scheduled by PublishAfter and PublishAt that are pending when the bus is drained.
+// The default is ScheduleDiscard.
+func WithSchedulePolicy(policy SchedulePolicy) EventbusOption {
+	return newFuncEventbusOption(func(o *eventbusOptions) {
+		o.schedulePolicy = policy
+	})
+}
+
+// WithDeadLetter returns a EventbusOption that publishes a DeadLetter to
+// <topic>.dlq for every delivery failing with a panic or a returned error.
+func WithDeadLetter() EventbusOption {
+	return newFuncEventbusOption(func(o *eventbusOptions) {
+		o.deadLetters = &deadLetters{}
+	})
+}
+
+// WithDeadLetterTopic returns a EventbusOption that publishes the DeadLetter
+// of every failed delivery to topic.
+func WithDeadLetterTopic(topic string) EventbusOption {
+	return newFuncEventbusOption(func(o *eventbusOptions) {
+		o.deadLetters = &deadLetters{topic: topic}
+	})
+}
+
+// WithMetrics returns a EventbusOption that reports the publishes and deliveries
+// of the bus to collector, like a PrometheusCollector or an ExpvarCollector.
+func WithMetrics(collector MetricsCollector) EventbusOption {
+	return newFuncEventbusOption(func(o *eventbusOptions) {
+		o.metrics = collector
+	})
+}
+
+// WithTracer returns a EventbusOption that traces the publishes and deliveries of
+// the bus with tracer. Publishes are not traced by default.
+func WithTracer(tracer Tracer) EventbusOption {
+	if tracer == nil {
+		tracer = noopTracer{}
+	}
+	return newFuncEventbusOption(func(o *eventbusOptions) {
+		o.tracer = tracer
+	})
+}
+
+type (
+	subscribeOptions struct {
+		queueSize    int
+		backpressure BackpressurePolicy
+		once         bool
+		priority     int
+		middleware   []Middleware
+		predicates   []func(args []interface{}) bool
+		fieldFilters []FieldFilter
+		replay       *Replay
+		rateLimit    *rateLimit
+		retry        *RetryPolicy
+		inactive     []func()
+	}
+
+	SubscribeOption interface {
+		apply(*subscribeOptions)
+	}
+)
+
+type funcSubscribeOption struct {
+	f func(options *subscribeOptions)
+}
+
+func (fso *funcSubscribeOption) apply(so *subscribeOptions) {
+	fso.f(so)
+}
+
+func newFuncSubscribeOption(f func(*subscribeOptions)) *funcSubscribeOption {
+	return &funcSubscribeOption{
+		f: f,
+	}
+}
+
+func newSubscribeOptions(opt []SubscribeOption) *subscribeOptions {
+	opts := &subscribeOptions{}
+	for _, o := range opt {
+		o.apply(opts)
+	}
+	return opts
+}
+
+// WithQueueSize returns a SubscribeOption that bounds the number of pending
+// events of an asynchronous subscriber. Zero means unbounded.
+func WithQueueSize(size int) SubscribeOption {
+	return newFuncSubscribeOption(func(o *subscribeOptions) {
+		o.queueSize = size
+	})
+}
+
+// WithBackpressure returns a SubscribeOption that sets the policy applied
+// when the queue of the subscriber is full. The default is BackpressureBlock.
+func WithBackpressure(policy BackpressurePolicy) SubscribeOption {
+	return newFuncSubscribeOption(func(o *subscribeOptions) {
+		o.backpressure = policy
+	})
+}
+
+// WithPriority returns a SubscribeOption that sets the priority of the subscription.
+// Subscribers of a topic receive events by descending priority, subscribers with
+// the same priority in subscribe order. The default priority is 0.
+func WithPriority(priority int) SubscribeOption {
+	return newFuncSubscribeOption(func(o *subscribeOptions) {
+		o.priority = priority
+	})
+}
+
+// WithSubscriptionMiddleware returns a SubscribeOption that wraps the deliveries
+// to the subscription in mw. It runs inside the bus and topic middleware.
+func WithSubscriptionMiddleware(mw ...Middleware) SubscribeOption {
+	return newFuncSubscribeOption(func(o *subscribeOptions) {
+		o.middleware = append(o.middleware, mw...)
+	})
+}
+
+// WithFilter returns a SubscribeOption that only delivers the events whose
+// arguments satisfy predicate. It is evaluated before dispatch.
+func WithFilter(predicate func(args []interface{}) bool) SubscribeOption {
+	return newFuncSubscribeOption(func(o *subscribeOptions) {
+		o.predicates = append(o.predicates, predicate)
+	})
+}
+
+// WithFieldFilter returns a SubscribeOption that only delivers the events matching
+// every filter. Unlike WithFilter, it is also applied by the remote endpoint of an RPCProxy.
+func WithFieldFilter(filters ...FieldFilter) SubscribeOption {
+	return newFuncSubscribeOption(func(o *subscribeOptions) {
+		o.fieldFilters = append(o.fieldFilters, filters...)
+	})
+}
+
+// WithDebounce returns a SubscribeOption that only delivers the last event of a burst,
+// once no event was published for d.
+func WithDebounce(d time.Duration) SubscribeOption {
+	return newFuncSubscribeOption(func(o *subscribeOptions) {
+		o.rateLimit = &rateLimit{mode: rateDebounce, interval: d}
+	})
+}
+
+// WithThrottle returns a SubscribeOption that delivers at most one event per interval d
+// and per edge, the first event of the interval with ThrottleLeading and the last one
+// with ThrottleTrailing.
+func WithThrottle(d time.Duration, edges ThrottleEdge) SubscribeOption {
+	return newFuncSubscribeOption(func(o *subscribeOptions) {
+		o.rateLimit = &rateLimit{mode: rateThrottle, interval: d, edges: edges}
+	})
+}
+
+// WithSample returns a SubscribeOption that delivers the latest event every d,
+// provided one was published since the previous delivery.
+func WithSample(d time.Duration) SubscribeOption {
+	return newFuncSubscribeOption(func(o *subscribeOptions) {
+		o.rateLimit = &rateLimit{mode: rateSample, interval: d}
+	})
+}
+
+// WithRetry returns a SubscribeOption that retries the failed deliveries to an
+// asynchronous subscriber by policy. Synchronous subscribers are not retried, so
+// that the publisher is not held up by the backoff.
+func WithRetry(policy RetryPolicy) SubscribeOption {
+	return newFuncSubscribeOption(func(o *subscribeOptions) {
+		o.retry = policy.withDefaults()
+	})
+}
+
+func withReplay(replay Replay) SubscribeOption {
+	return newFuncSubscribeOption(func(o *subscribeOptions) {
+		o.replay = &replay
+	})
+}
+
+func withOnce() SubscribeOption {
+	return newFuncSubscribeOption(func(o *subscribeOptions) {
+		o.once = true
+	})
+}
+
+// withInactive adds f to the functions called once the subscription is
+// unsubscribed or deactivated by its only delivery, but not when the bus is closed.
+func withInactive(f func()) SubscribeOption {
+	return newFuncSubscribeOption(func(o *subscribeOptions) {
+		o.inactive = append(o.inactive, f)
+	})
+}
+
+type (
+	rpcProxyOptions struct {
+		retry *RetryPolicy
+	}
+
+	RPCProxyOption interface {
+		apply(*rpcProxyOptions)
+	}
+)
+
+type funcRPCProxyOption struct {
+	f func(options *rpcProxyOptions)
+}
+
+func (fpo *funcRPCProxyOption) apply(po *rpcProxyOptions) {
+	fpo.f(po)
+}
+
+func newFuncRPCProxyOption(f func(*rpcProxyOptions)) *funcRPCProxyOption {
+	return &funcRPCProxyOption{
+		f: f,
+	}
+}
+
+// WithPublishRetry returns a RPCProxyOption that retries the events forwarded to
+// a remote subscriber by policy when they fail to be sent, for example when the
+// peer can not be dialed. The local publish waits for the retries.
+func WithPublishRetry(policy RetryPolicy) RPCProxyOption {
+	return newFuncRPCProxyOption(func(o *rpcProxyOptions) {
+		o.retry = policy.withDefaults()
+	})
+}
@@ -0,0 +1,19 @@
+module github.com/danielhookx/eventbus/oteleventbus
+
+go 1.20
+
+require (
+	github.com/danielhookx/eventbus v0.1.0
+	go.opentelemetry.io/otel v1.21.0
+	go.opentelemetry.io/otel/sdk v1.21.0
+	go.opentelemetry.io/otel/trace v1.21.0
+)
+
+require (
+	github.com/danielhookx/fission v0.1.0 // indirect
+	github.com/danielhookx/xcontainer v0.1.0 // indirect
+	github.com/go-logr/logr v1.3.0 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	go.opentelemetry.io/otel/metric v1.21.0 // indirect
+	golang.org/x/sys v0.14.0 // indirect
+)
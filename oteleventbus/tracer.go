@@ -0,0 +1,123 @@
+// Package oteleventbus traces the publishes and deliveries of an eventbus with OpenTelemetry.
+package oteleventbus
+
+import (
+	"context"
+
+	"github.com/danielhookx/eventbus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/danielhookx/eventbus/oteleventbus"
+
+type options struct {
+	provider   trace.TracerProvider
+	propagator propagation.TextMapPropagator
+}
+
+type Option interface {
+	apply(*options)
+}
+
+type funcOption struct {
+	f func(*options)
+}
+
+func (fo *funcOption) apply(o *options) {
+	fo.f(o)
+}
+
+func newFuncOption(f func(*options)) *funcOption {
+	return &funcOption{
+		f: f,
+	}
+}
+
+// WithTracerProvider returns an Option that sets the provider creating the spans,
+// the global one by default.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return newFuncOption(func(o *options) {
+		o.provider = provider
+	})
+}
+
+// WithPropagator returns an Option that sets the propagator carrying the trace
+// context to the peers of an RPCProxy, the global one by default.
+func WithPropagator(propagator propagation.TextMapPropagator) Option {
+	return newFuncOption(func(o *options) {
+		o.propagator = propagator
+	})
+}
+
+// Tracer is an eventbus.Tracer starting a producer span for every publish and
+// a consumer span for every delivery.
+type Tracer struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+func NewTracer(opt ...Option) *Tracer {
+	opts := options{}
+	for _, o := range opt {
+		o.apply(&opts)
+	}
+	if opts.provider == nil {
+		opts.provider = otel.GetTracerProvider()
+	}
+	if opts.propagator == nil {
+		opts.propagator = otel.GetTextMapPropagator()
+	}
+	return &Tracer{
+		tracer:     opts.provider.Tracer(instrumentationName),
+		propagator: opts.propagator,
+	}
+}
+
+func (t *Tracer) StartPublish(ctx context.Context, topic string) (context.Context, eventbus.Span) {
+	ctx, span := t.tracer.Start(ctx, "publish "+topic,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "eventbus"),
+			attribute.String("messaging.destination.name", topic),
+		),
+	)
+	return ctx, endSpan{span}
+}
+
+func (t *Tracer) StartDelivery(ctx context.Context, d *eventbus.Delivery) (context.Context, eventbus.Span) {
+	ctx, span := t.tracer.Start(ctx, "deliver "+d.Topic,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "eventbus"),
+			attribute.String("messaging.destination.name", d.Topic),
+			attribute.String("eventbus.pattern", d.Pattern),
+			attribute.String("eventbus.subscriber", d.Subscriber),
+		),
+	)
+	return ctx, endSpan{span}
+}
+
+func (t *Tracer) Inject(ctx context.Context, carrier eventbus.Metadata) {
+	t.propagator.Inject(ctx, propagation.MapCarrier(carrier))
+}
+
+func (t *Tracer) Extract(ctx context.Context, carrier eventbus.Metadata) context.Context {
+	return t.propagator.Extract(ctx, propagation.MapCarrier(carrier))
+}
+
+// endSpan records the error a span ends with.
+type endSpan struct {
+	span trace.Span
+}
+
+func (s endSpan) End(err error) {
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
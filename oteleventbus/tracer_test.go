@@ -0,0 +1,99 @@
+package oteleventbus
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/danielhookx/eventbus"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTestTracer() (*Tracer, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	return NewTracer(WithTracerProvider(provider), WithPropagator(propagation.TraceContext{})), recorder
+}
+
+func TestTracer(t *testing.T) {
+	tracer, recorder := newTestTracer()
+	bus := eventbus.New(eventbus.WithTracer(tracer), eventbus.WithErrorHandler(nil))
+	bus.SubscribeSync("orders.created", func(id string) error {
+		return errors.New("out of stock")
+	})
+	bus.Publish("orders.created", "o-1")
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	deliver, publish := spans[0], spans[1]
+	if publish.Name() != "publish orders.created" || publish.SpanKind() != trace.SpanKindProducer {
+		t.Fatalf("unexpected publish span %s %v", publish.Name(), publish.SpanKind())
+	}
+	if deliver.Name() != "deliver orders.created" || deliver.SpanKind() != trace.SpanKindConsumer {
+		t.Fatalf("unexpected delivery span %s %v", deliver.Name(), deliver.SpanKind())
+	}
+	if deliver.Parent().SpanID() != publish.SpanContext().SpanID() {
+		t.Fatal("expected the delivery span to be a child of the publish span")
+	}
+	if deliver.Status().Code != codes.Error || deliver.Status().Description != "out of stock" {
+		t.Fatalf("unexpected delivery status %v", deliver.Status())
+	}
+}
+
+func TestTracerRPCProxy(t *testing.T) {
+	dir := t.TempDir()
+	a := "unix://" + filepath.Join(dir, "a.sock")
+	b := "unix://" + filepath.Join(dir, "b.sock")
+	pubTracer, pubRecorder := newTestTracer()
+	subTracer, subRecorder := newTestTracer()
+	pub, err := eventbus.NewRPCProxy(a, b, eventbus.New(eventbus.WithTracer(pubTracer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub, err := eventbus.NewRPCProxy(b, a, eventbus.New(eventbus.WithTracer(subTracer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		pub.Close(context.Background())
+		sub.Close(context.Background())
+	})
+	done := make(chan struct{})
+	sub.SubscribeSync("orders.created", func(id string) {
+		close(done)
+	})
+	pub.Publish("orders.created", "o-1")
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("remote subscriber was not called")
+	}
+	sub.Drain(context.Background())
+
+	pubSpans := pubRecorder.Ended()
+	if len(pubSpans) != 2 {
+		t.Fatalf("expected 2 local spans, got %d", len(pubSpans))
+	}
+	traceID := pubSpans[1].SpanContext().TraceID()
+	forward := pubSpans[0].SpanContext().SpanID()
+	subSpans := subRecorder.Ended()
+	if len(subSpans) != 2 {
+		t.Fatalf("expected 2 remote spans, got %d", len(subSpans))
+	}
+	for _, s := range subSpans {
+		if s.SpanContext().TraceID() != traceID {
+			t.Fatalf("expected remote span %s in trace %s, got %s", s.Name(), traceID, s.SpanContext().TraceID())
+		}
+	}
+	if remotePublish := subSpans[1]; remotePublish.Parent().SpanID() != forward || !remotePublish.Parent().IsRemote() {
+		t.Fatal("expected the remote publish span to be a child of the forwarding delivery span")
+	}
+}
@@ -0,0 +1,80 @@
+package eventbus
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// WorkerPool runs asynchronous deliveries on a fixed number of goroutines.
+// Submit blocks while the queue is full.
+type WorkerPool struct {
+	size      int
+	tasks     chan func()
+	closeOnce sync.Once
+	active    atomic.Int64
+	submitted atomic.Uint64
+	completed atomic.Uint64
+}
+
+type PoolStats struct {
+	Workers       int
+	Active        int64
+	QueueDepth    int
+	QueueCapacity int
+	Submitted     uint64
+	Completed     uint64
+}
+
+func NewWorkerPool(size, queueLen int) *WorkerPool {
+	if size <= 0 {
+		size = 1
+	}
+	if queueLen < 0 {
+		queueLen = 0
+	}
+	p := &WorkerPool{
+		size:  size,
+		tasks: make(chan func(), queueLen),
+	}
+	for i := 0; i < size; i++ {
+		go p.work()
+	}
+	return p
+}
+
+func (p *WorkerPool) Submit(task func()) {
+	p.submitted.Add(1)
+	p.tasks <- task
+}
+
+// Close stops the workers once the queued tasks are done, Submit must not be called afterwards.
+// A bus does not close the pool it was given, so one pool can be shared by several buses.
+func (p *WorkerPool) Close() {
+	p.closeOnce.Do(func() {
+		close(p.tasks)
+	})
+}
+
+func (p *WorkerPool) Stats() PoolStats {
+	return PoolStats{
+		Workers:       p.size,
+		Active:        p.active.Load(),
+		QueueDepth:    len(p.tasks),
+		QueueCapacity: cap(p.tasks),
+		Submitted:     p.submitted.Load(),
+		Completed:     p.completed.Load(),
+	}
+}
+
+func (p *WorkerPool) work() {
+	for task := range p.tasks {
+		p.active.Add(1)
+		task()
+		p.active.Add(-1)
+		p.completed.Add(1)
+	}
+}
+
+func goExecute(task func()) {
+	go task()
+}
@@ -0,0 +1,69 @@
+package eventbus
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWorkerPoolSubscribe(t *testing.T) {
+	pool := NewWorkerPool(2, 16)
+	e := New(WithWorkerPool(pool))
+	topic := "testpub1"
+	var running, maxRunning atomic.Int64
+	wg := sync.WaitGroup{}
+	wg.Add(10)
+	e.Subscribe(topic, func(name string) {
+		defer wg.Done()
+		n := running.Add(1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond * 10)
+		running.Add(-1)
+	})
+	for i := 0; i < 10; i++ {
+		e.Publish(topic, "jack")
+	}
+	wg.Wait()
+	if maxRunning.Load() > 2 {
+		t.Fatalf("expected at most 2 concurrent handlers, got %d", maxRunning.Load())
+	}
+	stats := pool.Stats()
+	if stats.Workers != 2 || stats.QueueCapacity != 16 || stats.Submitted != 10 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestWorkerPoolStats(t *testing.T) {
+	pool := NewWorkerPool(1, 4)
+	block := make(chan struct{})
+	started := make(chan struct{})
+	pool.Submit(func() {
+		close(started)
+		<-block
+	})
+	<-started
+	pool.Submit(func() {})
+	pool.Submit(func() {})
+	stats := pool.Stats()
+	if stats.Active != 1 || stats.QueueDepth != 2 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	close(block)
+}
+
+func BenchmarkSubPubPool(b *testing.B) {
+	e := New(WithWorkerPool(NewWorkerPool(8, 1024)))
+	topic := "testpub1"
+	e.Subscribe(topic, func(name string) {})
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			e.Publish(topic, "jack")
+		}
+	})
+}
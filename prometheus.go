@@ -0,0 +1,137 @@
+package eventbus
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// PrometheusCollector is a MetricsCollector serving its measurements in the
+// Prometheus text exposition format.
+type PrometheusCollector struct {
+	*metricsStore
+}
+
+// NewPrometheusCollector returns a PrometheusCollector with the given histogram
+// buckets in seconds, DefaultBuckets when none are given.
+func NewPrometheusCollector(buckets ...float64) *PrometheusCollector {
+	return &PrometheusCollector{
+		metricsStore: newMetricsStore(buckets),
+	}
+}
+
+func (c *PrometheusCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.WriteTo(w)
+}
+
+// WriteTo writes the measurements in the Prometheus text exposition format.
+func (c *PrometheusCollector) WriteTo(w io.Writer) (int64, error) {
+	s := c.Snapshot()
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+
+	writeHelp(cw, "eventbus_published_total", "counter", "Events published per topic.")
+	for _, t := range s.Topics {
+		writeSample(cw, "eventbus_published_total", topicLabels(t), float64(t.Published))
+	}
+	writeHelp(cw, "eventbus_publish_errors_total", "counter", "Publishes per topic that returned an error.")
+	for _, t := range s.Topics {
+		writeSample(cw, "eventbus_publish_errors_total", topicLabels(t), float64(t.Errors))
+	}
+	writeHelp(cw, "eventbus_delivered_total", "counter", "Delivery attempts per topic and subscriber.")
+	for _, sub := range s.Subscribers {
+		writeSample(cw, "eventbus_delivered_total", subscriberLabels(sub), float64(sub.Delivered))
+	}
+	writeHelp(cw, "eventbus_delivery_errors_total", "counter", "Failed delivery attempts per topic and subscriber.")
+	for _, sub := range s.Subscribers {
+		writeSample(cw, "eventbus_delivery_errors_total", subscriberLabels(sub), float64(sub.Errors))
+	}
+	writeHelp(cw, "eventbus_delivery_latency_seconds", "histogram", "Time from the publish to the start of a delivery.")
+	for _, sub := range s.Subscribers {
+		writeHistogram(cw, "eventbus_delivery_latency_seconds", subscriberLabels(sub), sub.Latency)
+	}
+	writeHelp(cw, "eventbus_handler_duration_seconds", "histogram", "Time the handlers took.")
+	for _, sub := range s.Subscribers {
+		writeHistogram(cw, "eventbus_handler_duration_seconds", subscriberLabels(sub), sub.Duration)
+	}
+	writeHelp(cw, "eventbus_async_tasks", "gauge", "Asynchronous delivery tasks running.")
+	writeSample(cw, "eventbus_async_tasks", nil, float64(s.AsyncRunning))
+	writeHelp(cw, "eventbus_async_tasks_started_total", "counter", "Asynchronous delivery tasks started.")
+	writeSample(cw, "eventbus_async_tasks_started_total", nil, float64(s.AsyncStarted))
+
+	if cw.err == nil {
+		cw.err = cw.w.Flush()
+	}
+	return cw.n, cw.err
+}
+
+// countingWriter keeps the first error, so that the exposition is written without checking each line.
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	w.err = err
+	return n, err
+}
+
+type label struct {
+	name  string
+	value string
+}
+
+func topicLabels(t TopicMetrics) []label {
+	return []label{{"topic", t.Topic}}
+}
+
+func subscriberLabels(s SubscriberMetrics) []label {
+	return []label{{"topic", s.Topic}, {"pattern", s.Pattern}, {"subscriber", s.Subscriber}}
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatLabels(labels []label) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	parts := make([]string, len(labels))
+	for i, l := range labels {
+		parts[i] = l.name + `="` + labelEscaper.Replace(l.value) + `"`
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func formatValue(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func writeHelp(w io.Writer, name, typ, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+func writeSample(w io.Writer, name string, labels []label, v float64) {
+	fmt.Fprintf(w, "%s%s %s\n", name, formatLabels(labels), formatValue(v))
+}
+
+func writeHistogram(w io.Writer, name string, labels []label, h HistogramMetrics) {
+	for i, bound := range h.Buckets {
+		writeSample(w, name+"_bucket", append(labels[:len(labels):len(labels)], label{"le", formatValue(bound)}), float64(h.Counts[i]))
+	}
+	writeSample(w, name+"_bucket", append(labels[:len(labels):len(labels)], label{"le", "+Inf"}), float64(h.Count))
+	writeSample(w, name+"_sum", labels, h.Sum)
+	writeSample(w, name+"_count", labels, float64(h.Count))
+}
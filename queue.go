@@ -0,0 +1,202 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+var ErrQueueFull = errors.New("subscriber queue is full")
+
+// BackpressurePolicy decides what happens to an event published while the
+// queue of a subscriber is full.
+type BackpressurePolicy int
+
+const (
+	// BackpressureBlock blocks the publisher until the queue has room.
+	BackpressureBlock BackpressurePolicy = iota
+	// BackpressureDropNewest discards the event being published.
+	BackpressureDropNewest
+	// BackpressureDropOldest discards the oldest queued event.
+	BackpressureDropOldest
+	// BackpressureFail discards the event and fails the publish with ErrQueueFull.
+	BackpressureFail
+)
+
+func (p BackpressurePolicy) String() string {
+	switch p {
+	case BackpressureBlock:
+		return "block"
+	case BackpressureDropNewest:
+		return "drop-newest"
+	case BackpressureDropOldest:
+		return "drop-oldest"
+	case BackpressureFail:
+		return "fail"
+	}
+	return "unknown"
+}
+
+type QueueStats struct {
+	Topic    string
+	Handler  string
+	Policy   BackpressurePolicy
+	Len      int
+	Capacity int
+	Dropped  uint64
+}
+
+// queuedDistribution keeps the pending events of a single subscriber.
+// An ordered distribution delivers them one at a time in publish order,
+// otherwise every event is delivered by its own task.
+// A queue with a positive size counts running deliveries against it and
+// applies the backpressure policy once it is full.
+type queuedDistribution struct {
+	topic   string
+	fn      reflect.Value
+	inv     *invoker
+	execute func(task func())
+	ordered bool
+	size    int
+	policy  BackpressurePolicy
+
+	lock      sync.Mutex
+	notFull   *sync.Cond
+	queue     []*event
+	running   int
+	scheduled bool
+	dropped   atomic.Uint64
+}
+
+func newQueuedDistribution(topic string, fn reflect.Value, inv *invoker, execute func(task func()), ordered bool, opts *subscribeOptions) *queuedDistribution {
+	d := &queuedDistribution{
+		topic:   topic,
+		fn:      fn,
+		inv:     inv,
+		execute: execute,
+		ordered: ordered,
+		size:    opts.queueSize,
+		policy:  opts.backpressure,
+	}
+	d.notFull = sync.NewCond(&d.lock)
+	return d
+}
+
+func (d *queuedDistribution) Register(ctx context.Context) {
+	return
+}
+
+func (d *queuedDistribution) Key() any {
+	return d.fn.Pointer()
+}
+
+func (d *queuedDistribution) Dist(data any) error {
+	ev := data.(*event)
+	d.lock.Lock()
+	for d.size > 0 && len(d.queue)+d.running >= d.size {
+		switch d.policy {
+		case BackpressureDropOldest:
+			if len(d.queue) > 0 {
+				d.queue[0].respond(reply{err: &HandlerError{
+					Topic:   d.topic,
+					Handler: funcName(d.fn),
+					Err:     ErrQueueFull,
+				}})
+				d.queue[0] = nil
+				d.queue = d.queue[1:]
+				d.dropped.Add(1)
+				continue
+			}
+			// every slot is taken by a running delivery, nothing older to drop
+			fallthrough
+		case BackpressureDropNewest:
+			d.lock.Unlock()
+			d.dropped.Add(1)
+			return nil
+		case BackpressureFail:
+			d.lock.Unlock()
+			d.dropped.Add(1)
+			ev.fail(&HandlerError{
+				Topic:   d.topic,
+				Handler: funcName(d.fn),
+				Err:     ErrQueueFull,
+			})
+			return nil
+		default:
+			d.notFull.Wait()
+		}
+	}
+	d.queue = append(d.queue, ev)
+	ev.expectReply()
+	if d.ordered {
+		if d.scheduled {
+			d.lock.Unlock()
+			return nil
+		}
+		d.scheduled = true
+		d.lock.Unlock()
+		d.execute(d.drain)
+		return nil
+	}
+	d.lock.Unlock()
+	d.execute(d.deliverOne)
+	return nil
+}
+
+// drain runs as the only consumer of an ordered queue and exits once it is empty.
+func (d *queuedDistribution) drain() {
+	for {
+		ev, ok := d.pop()
+		if !ok {
+			return
+		}
+		d.deliver(ev)
+	}
+}
+
+func (d *queuedDistribution) deliverOne() {
+	if ev, ok := d.pop(); ok {
+		d.deliver(ev)
+	}
+}
+
+func (d *queuedDistribution) pop() (*event, bool) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if len(d.queue) == 0 {
+		d.scheduled = false
+		return nil, false
+	}
+	ev := d.queue[0]
+	d.queue[0] = nil
+	d.queue = d.queue[1:]
+	d.running++
+	return ev, true
+}
+
+func (d *queuedDistribution) deliver(ev *event) {
+	ev.respond(d.inv.invokeRetry(detachContext(ev.ctx), ev, funcHandler(d.fn)))
+	d.lock.Lock()
+	d.running--
+	d.notFull.Broadcast()
+	d.lock.Unlock()
+}
+
+func (d *queuedDistribution) stats() QueueStats {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return QueueStats{
+		Topic:    d.topic,
+		Handler:  funcName(d.fn),
+		Policy:   d.policy,
+		Len:      len(d.queue),
+		Capacity: d.size,
+		Dropped:  d.dropped.Load(),
+	}
+}
+
+func (d *queuedDistribution) Close() error {
+	return nil
+}
@@ -0,0 +1,165 @@
+package eventbus
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSubscribeOrdered(t *testing.T) {
+	e := New()
+	topic := "testpub1"
+	n := 100
+	wg := sync.WaitGroup{}
+	wg.Add(n)
+	var got []int
+	e.SubscribeOrdered(topic, func(i int) {
+		defer wg.Done()
+		if i%10 == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		got = append(got, i)
+	})
+	for i := 0; i < n; i++ {
+		e.Publish(topic, i)
+	}
+	wg.Wait()
+	for i, v := range got {
+		if v != i {
+			t.Fatalf("expected event %d at position %d, got %d", i, i, v)
+		}
+	}
+}
+
+func TestSubscribeOrderedNotBlocking(t *testing.T) {
+	e := New()
+	topic := "testpub1"
+	release := make(chan struct{})
+	done := make(chan struct{})
+	e.SubscribeOrdered(topic, func(i int) {
+		<-release
+		if i == 2 {
+			close(done)
+		}
+	})
+	e.Publish(topic, 0)
+	e.Publish(topic, 1)
+	e.Publish(topic, 2)
+	close(release)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("ordered subscriber did not receive all events")
+	}
+}
+
+func TestSubscribeOrderedWithPool(t *testing.T) {
+	e := New(WithWorkerPool(NewWorkerPool(4, 16)))
+	topic := "testpub1"
+	n := 50
+	wg := sync.WaitGroup{}
+	wg.Add(n)
+	next := 0
+	e.SubscribeOrdered(topic, func(i int) {
+		defer wg.Done()
+		if i != next {
+			t.Errorf("expected %d, got %d", next, i)
+		}
+		next++
+	})
+	for i := 0; i < n; i++ {
+		e.Publish(topic, i)
+	}
+	wg.Wait()
+}
+
+func TestBackpressurePolicies(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   BackpressurePolicy
+		expected []int
+		dropped  uint64
+		failed   int
+	}{
+		{"drop newest", BackpressureDropNewest, []int{0, 1, 2}, 2, 0},
+		{"drop oldest", BackpressureDropOldest, []int{0, 3, 4}, 2, 0},
+		{"fail", BackpressureFail, []int{0, 1, 2}, 2, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := New()
+			topic := "testpub1"
+			release := make(chan struct{})
+			started := make(chan struct{})
+			var got []int
+			wg := sync.WaitGroup{}
+			wg.Add(len(tt.expected))
+			e.SubscribeOrdered(topic, func(i int) {
+				defer wg.Done()
+				if i == 0 {
+					close(started)
+				}
+				<-release
+				got = append(got, i)
+			}, WithQueueSize(3), WithBackpressure(tt.policy))
+
+			e.PublishE(topic, 0)
+			<-started
+			failed := 0
+			for i := 1; i < 5; i++ {
+				if err := e.PublishE(topic, i); err != nil {
+					if !errors.Is(err, ErrQueueFull) {
+						t.Fatalf("unexpected error %v", err)
+					}
+					failed++
+				}
+			}
+			stats := e.QueueStats(topic)
+			if len(stats) != 1 || stats[0].Dropped != tt.dropped || stats[0].Capacity != 3 {
+				t.Fatalf("unexpected stats %+v", stats)
+			}
+			close(release)
+			wg.Wait()
+			if failed != tt.failed {
+				t.Fatalf("expected %d failed publishes, got %d", tt.failed, failed)
+			}
+			if len(got) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Fatalf("expected %v, got %v", tt.expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestBackpressureBlock(t *testing.T) {
+	e := New()
+	topic := "testpub1"
+	release := make(chan struct{})
+	e.Subscribe(topic, func(i int) {
+		<-release
+	}, WithQueueSize(2))
+	e.Publish(topic, 0)
+	e.Publish(topic, 1)
+
+	published := make(chan struct{})
+	go func() {
+		e.Publish(topic, 2)
+		close(published)
+	}()
+	select {
+	case <-published:
+		t.Fatal("publish should block while the queue is full")
+	case <-time.After(time.Millisecond * 50):
+	}
+	close(release)
+	select {
+	case <-published:
+	case <-time.After(time.Second):
+		t.Fatal("publish was not released")
+	}
+}
@@ -0,0 +1,161 @@
+package eventbus
+
+import (
+	"sync"
+	"time"
+)
+
+// ThrottleEdge selects the events a throttled subscription delivers in each interval.
+type ThrottleEdge int
+
+const (
+	// ThrottleLeading delivers the first event of an interval right away.
+	ThrottleLeading ThrottleEdge = 1 << iota
+	// ThrottleTrailing delivers the last event of an interval when it ends.
+	ThrottleTrailing
+)
+
+type rateMode int
+
+const (
+	rateDebounce rateMode = iota
+	rateThrottle
+	rateSample
+)
+
+type rateLimit struct {
+	mode     rateMode
+	interval time.Duration
+	edges    ThrottleEdge
+}
+
+// rateLimiter decides when the events of a subscription are delivered.
+// Delayed deliveries happen on the goroutine of its timer, as long as acquire
+// succeeds. The event held back once the bus is drained is returned by stop.
+type rateLimiter struct {
+	rateLimit
+	deliver func(ev *event)
+	acquire func() error
+	release func()
+
+	lock    sync.Mutex
+	timer   *time.Timer
+	running bool
+	stopped bool
+	pending *event
+}
+
+func newRateLimiter(limit rateLimit, deliver func(ev *event), acquire func() error, release func()) *rateLimiter {
+	return &rateLimiter{
+		rateLimit: limit,
+		deliver:   deliver,
+		acquire:   acquire,
+		release:   release,
+	}
+}
+
+// start arms the timer for the next interval, the lock has to be held.
+func (l *rateLimiter) start() {
+	l.running = true
+	if l.timer == nil {
+		l.timer = time.AfterFunc(l.interval, l.fire)
+		return
+	}
+	l.timer.Reset(l.interval)
+}
+
+func (l *rateLimiter) offer(ev *event) {
+	l.lock.Lock()
+	if l.stopped {
+		// nothing is held back anymore once the limiter was stopped
+		l.lock.Unlock()
+		l.deliver(ev)
+		return
+	}
+	switch l.mode {
+	case rateDebounce:
+		l.pending = ev
+		l.start()
+	case rateThrottle:
+		if !l.running {
+			l.start()
+			if l.edges&ThrottleLeading != 0 {
+				l.lock.Unlock()
+				l.deliver(ev)
+				return
+			}
+		}
+		if l.edges&ThrottleTrailing != 0 {
+			l.pending = ev
+		}
+	case rateSample:
+		l.pending = ev
+		if !l.running {
+			l.start()
+		}
+	}
+	l.lock.Unlock()
+}
+
+func (l *rateLimiter) fire() {
+	// the pending event is left to stop once the bus is drained
+	if l.acquire() != nil {
+		return
+	}
+	defer l.release()
+	l.lock.Lock()
+	if l.stopped {
+		l.lock.Unlock()
+		return
+	}
+	ev := l.pending
+	l.pending = nil
+	switch {
+	case ev == nil:
+		l.running = false
+	case l.mode == rateDebounce:
+		l.running = false
+	default:
+		// a trailing or sampled delivery opens the next interval
+		l.start()
+	}
+	l.lock.Unlock()
+	if ev != nil {
+		l.deliver(ev)
+	}
+}
+
+// stop stops the timer and returns the event held back, if any.
+func (l *rateLimiter) stop() *event {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.stopped = true
+	l.running = false
+	if l.timer != nil {
+		l.timer.Stop()
+	}
+	ev := l.pending
+	l.pending = nil
+	return ev
+}
+
+func (bus *EventBus) limit(sub *subscription, opts *subscribeOptions) {
+	if opts.rateLimit == nil {
+		return
+	}
+	sub.limiter = newRateLimiter(*opts.rateLimit, func(ev *event) {
+		sub.dist(ev)
+	}, bus.acquire, bus.release)
+}
+
+// flushLimiters delivers the events held back by the rate limiters and stops their timers.
+func (bus *EventBus) flushLimiters() {
+	for _, sub := range bus.subs.all() {
+		if sub.limiter == nil {
+			continue
+		}
+		if ev := sub.limiter.stop(); ev != nil {
+			sub.dist(ev)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package eventbus
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type intRecorder struct {
+	lock sync.Mutex
+	got  []int
+}
+
+func (r *intRecorder) record(i int) {
+	r.lock.Lock()
+	r.got = append(r.got, i)
+	r.lock.Unlock()
+}
+
+func (r *intRecorder) expect(t *testing.T, expected ...int) {
+	t.Helper()
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if len(expected) == 0 && len(r.got) == 0 {
+		return
+	}
+	if !reflect.DeepEqual(r.got, expected) {
+		t.Fatalf("expected %v, got %v", expected, r.got)
+	}
+}
+
+func TestDebounce(t *testing.T) {
+	e := New()
+	topic := "refresh"
+	r := &intRecorder{}
+	e.SubscribeSync(topic, r.record, WithDebounce(50*time.Millisecond))
+	for i := 1; i <= 5; i++ {
+		e.Publish(topic, i)
+	}
+	r.expect(t)
+	time.Sleep(150 * time.Millisecond)
+	r.expect(t, 5)
+	e.Publish(topic, 6)
+	time.Sleep(150 * time.Millisecond)
+	r.expect(t, 5, 6)
+}
+
+func TestThrottle(t *testing.T) {
+	tests := []struct {
+		edges     ThrottleEdge
+		immediate []int
+		later     []int
+	}{
+		{ThrottleLeading, []int{1}, []int{1}},
+		{ThrottleTrailing, nil, []int{3}},
+		{ThrottleLeading | ThrottleTrailing, []int{1}, []int{1, 3}},
+	}
+	for _, tt := range tests {
+		e := New()
+		topic := "refresh"
+		r := &intRecorder{}
+		e.SubscribeSync(topic, r.record, WithThrottle(100*time.Millisecond, tt.edges))
+		for i := 1; i <= 3; i++ {
+			e.Publish(topic, i)
+		}
+		r.expect(t, tt.immediate...)
+		time.Sleep(300 * time.Millisecond)
+		r.expect(t, tt.later...)
+		e.Publish(topic, 4)
+		if tt.edges&ThrottleLeading != 0 {
+			r.expect(t, append(tt.later, 4)...)
+		}
+	}
+}
+
+func TestSample(t *testing.T) {
+	e := New()
+	topic := "refresh"
+	r := &intRecorder{}
+	e.Subscribe(topic, r.record, WithSample(50*time.Millisecond))
+	e.Publish(topic, 1)
+	e.Publish(topic, 2)
+	time.Sleep(120 * time.Millisecond)
+	e.Drain(context.Background())
+	r.expect(t, 2)
+}
+
+func TestRateLimitDrain(t *testing.T) {
+	e := New()
+	topic := "refresh"
+	r := &intRecorder{}
+	e.SubscribeSync(topic, r.record, WithDebounce(200*time.Millisecond))
+	e.Subscribe(topic, r.record, WithSample(200*time.Millisecond))
+	e.Publish(topic, 1)
+	if err := e.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// the held back events are delivered by Drain, and only once
+	r.expect(t, 1, 1)
+	time.Sleep(300 * time.Millisecond)
+	r.expect(t, 1, 1)
+}
@@ -0,0 +1,214 @@
+package eventbus
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Replay selects the buffered events delivered by SubscribeWithReplay.
+// Zero values do not limit the selection.
+type Replay struct {
+	// Last is the maximum number of events replayed, the most recent ones are kept.
+	Last int
+	// Since only replays the events published within this duration.
+	Since time.Duration
+}
+
+func ReplayLast(n int) Replay {
+	return Replay{Last: n}
+}
+
+func ReplaySince(d time.Duration) Replay {
+	return Replay{Since: d}
+}
+
+type replayConfig struct {
+	pattern string
+	size    int
+}
+
+type replayEntry struct {
+	seq   uint64
+	time  time.Time
+	topic string
+	args  []interface{}
+}
+
+// ring keeps the last events of a topic.
+type ring struct {
+	entries []replayEntry
+	next    int
+	full    bool
+}
+
+func (r *ring) add(e replayEntry) {
+	r.entries[r.next] = e
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+func (r *ring) all() []replayEntry {
+	if !r.full {
+		return r.entries[:r.next]
+	}
+	return append(r.entries[r.next:len(r.entries):len(r.entries)], r.entries[:r.next]...)
+}
+
+// replayBuffers numbers the events of the buffered topics and keeps the last ones.
+type replayBuffers struct {
+	configs []replayConfig
+	lock    sync.Mutex
+	seq     uint64
+	rings   map[string]*ring
+}
+
+func newReplayBuffers(configs []replayConfig) *replayBuffers {
+	return &replayBuffers{
+		configs: configs,
+		rings:   make(map[string]*ring),
+	}
+}
+
+func (b *replayBuffers) size(topic string) int {
+	for _, c := range b.configs {
+		if matchTopic(c.pattern, topic) {
+			return c.size
+		}
+	}
+	return 0
+}
+
+// record buffers ev when its topic is buffered. It has to be called before the
+// subscribers of ev are looked up, so that a replaying subscription either finds
+// ev in the buffer or receives it live.
+func (b *replayBuffers) record(ev *event) {
+	size := b.size(ev.topic)
+	if size <= 0 {
+		return
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	r, ok := b.rings[ev.topic]
+	if !ok {
+		r = &ring{entries: make([]replayEntry, size)}
+		b.rings[ev.topic] = r
+	}
+	b.seq++
+	ev.seq = b.seq
+	r.add(replayEntry{
+		seq:   ev.seq,
+		time:  time.Now(),
+		topic: ev.topic,
+		args:  ev.args,
+	})
+}
+
+// snapshot returns the buffered events of the topics matching pattern selected by replay
+// in publish order, and the sequence number of the last event buffered so far.
+func (b *replayBuffers) snapshot(pattern string, replay Replay) ([]replayEntry, uint64) {
+	b.lock.Lock()
+	last := b.seq
+	var entries []replayEntry
+	for topic, r := range b.rings {
+		if matchTopic(pattern, topic) {
+			entries = append(entries, r.all()...)
+		}
+	}
+	b.lock.Unlock()
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].seq < entries[j].seq
+	})
+	if replay.Since > 0 {
+		since := time.Now().Add(-replay.Since)
+		i := sort.Search(len(entries), func(i int) bool {
+			return !entries[i].time.Before(since)
+		})
+		entries = entries[i:]
+	}
+	if replay.Last > 0 && len(entries) > replay.Last {
+		entries = entries[len(entries)-replay.Last:]
+	}
+	return entries, last
+}
+
+// replayGate holds the live events of a subscription until its replay is done
+// and drops the ones that were replayed. An event is buffered before its subscribers
+// are looked up, so it may also reach the subscription after the gate opened.
+type replayGate struct {
+	lock    sync.Mutex
+	live    bool
+	last    uint64
+	pending []*event
+}
+
+// admit reports whether ev is to be delivered now, it is held while the gate is closed.
+func (g *replayGate) admit(ev *event) bool {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if !g.live {
+		g.pending = append(g.pending, ev)
+		return false
+	}
+	return g.fresh(ev)
+}
+
+func (g *replayGate) fresh(ev *event) bool {
+	return ev.seq == 0 || ev.seq > g.last
+}
+
+// open delivers the held events not replayed up to last and lets the following ones through.
+func (g *replayGate) open(last uint64, deliver func(ev *event)) {
+	g.lock.Lock()
+	g.last = last
+	g.lock.Unlock()
+	for {
+		g.lock.Lock()
+		pending := g.pending
+		g.pending = nil
+		if len(pending) == 0 {
+			g.live = true
+			g.lock.Unlock()
+			return
+		}
+		g.lock.Unlock()
+		for _, ev := range pending {
+			if g.fresh(ev) {
+				deliver(ev)
+			}
+		}
+	}
+}
+
+// SubscribeWithReplay subscribes fn like SubscribeOrdered, delivering the buffered events
+// of topic selected by replay before the live ones. Events published during the handover
+// are delivered once, after the replayed ones. Topics are buffered with WithReplayBuffer.
+func (bus *EventBus) SubscribeWithReplay(topic string, fn interface{}, replay Replay, opt ...SubscribeOption) (Subscription, error) {
+	opt = append(opt, withReplay(replay))
+	return bus.SubscribeOrdered(topic, fn, opt...)
+}
+
+// replayTo delivers the buffered events to sub and then opens its gate.
+func (bus *EventBus) replayTo(sub *subscription, replay Replay) {
+	entries, last := bus.replay.snapshot(sub.topic, replay)
+	for _, e := range entries {
+		if err := bus.sigs.check(sub.topic, e.args); err != nil {
+			continue
+		}
+		ev := newEvent(context.Background(), e.topic, e.args)
+		ev.seq = e.seq
+		if !sub.accepts(ev) {
+			continue
+		}
+		sub.dist(ev)
+		if err := ev.err(); err != nil {
+			bus.onError(err)
+		}
+	}
+	sub.gate.open(last, func(ev *event) {
+		sub.dist(ev)
+	})
+}
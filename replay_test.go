@@ -0,0 +1,115 @@
+package eventbus
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func collectInts(t *testing.T, e Eventbus, ch chan int, n int) []int {
+	t.Helper()
+	var got []int
+	for len(got) < n {
+		select {
+		case i := <-ch:
+			got = append(got, i)
+		case <-time.After(time.Second):
+			t.Fatalf("expected %d events, got %v", n, got)
+		}
+	}
+	return got
+}
+
+func TestSubscribeWithReplay(t *testing.T) {
+	e := New(WithReplayBuffer(3, "audit"))
+	topic := "audit"
+	for i := 1; i <= 5; i++ {
+		e.Publish(topic, i)
+	}
+	all := make(chan int, 10)
+	e.SubscribeWithReplay(topic, func(i int) {
+		all <- i
+	}, Replay{})
+	last := make(chan int, 10)
+	e.SubscribeWithReplay(topic, func(i int) {
+		last <- i
+	}, ReplayLast(2))
+	e.Publish(topic, 6)
+
+	if got := collectInts(t, e, all, 4); !reflect.DeepEqual(got, []int{3, 4, 5, 6}) {
+		t.Fatalf("unexpected deliveries %v", got)
+	}
+	if got := collectInts(t, e, last, 3); !reflect.DeepEqual(got, []int{4, 5, 6}) {
+		t.Fatalf("unexpected deliveries %v", got)
+	}
+}
+
+func TestSubscribeWithReplaySince(t *testing.T) {
+	e := New(WithReplayBuffer(10, "audit"))
+	topic := "audit"
+	e.Publish(topic, 1)
+	time.Sleep(50 * time.Millisecond)
+	e.Publish(topic, 2)
+	ch := make(chan int, 10)
+	e.SubscribeWithReplay(topic, func(i int) {
+		ch <- i
+	}, ReplaySince(25*time.Millisecond))
+	if got := collectInts(t, e, ch, 1); !reflect.DeepEqual(got, []int{2}) {
+		t.Fatalf("unexpected deliveries %v", got)
+	}
+}
+
+func TestSubscribeWithReplayWildcard(t *testing.T) {
+	e := New(WithReplayBuffer(10, "audit.#"))
+	e.Publish("audit.login", 1)
+	e.Publish("audit.logout", 2)
+	e.Publish("audit.login", 3)
+	e.Publish("orders", 4)
+	ch := make(chan int, 10)
+	e.SubscribeWithReplay("audit.*", func(i int) {
+		ch <- i
+	}, Replay{})
+	if got := collectInts(t, e, ch, 3); !reflect.DeepEqual(got, []int{1, 2, 3}) {
+		t.Fatalf("unexpected deliveries %v", got)
+	}
+}
+
+func TestSubscribeWithReplayHandover(t *testing.T) {
+	const n = 2000
+	e := New(WithReplayBuffer(n, "audit"))
+	topic := "audit"
+	var wg sync.WaitGroup
+	wg.Add(1)
+	started := make(chan struct{})
+	go func() {
+		defer wg.Done()
+		for i := 1; i <= n; i++ {
+			if i == n/4 {
+				close(started)
+			}
+			e.Publish(topic, i)
+		}
+	}()
+	<-started
+	ch := make(chan int, n)
+	e.SubscribeWithReplay(topic, func(i int) {
+		ch <- i
+	}, Replay{})
+	wg.Wait()
+	if err := e.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	close(ch)
+	expected := 1
+	for i := range ch {
+		if i != expected {
+			t.Fatalf("expected %d, got %d", expected, i)
+		}
+		expected++
+	}
+	if expected != n+1 {
+		t.Fatalf("expected %d events, got %d", n, expected-1)
+	}
+}
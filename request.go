@@ -0,0 +1,148 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+)
+
+var ErrNoResponders = errors.New("no responders")
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+type BusRequester interface {
+	Request(ctx context.Context, topic string, args ...interface{}) ([]interface{}, error)
+	RequestAll(ctx context.Context, topic string, args ...interface{}) ([][]interface{}, error)
+}
+
+// Request delivers args to the subscribers of topic whose handlers return values
+// and returns the return values of the first one that succeeds. A trailing error
+// return value is reported as the error of the reply instead.
+// Subscribers without return values do not receive requests.
+func (bus *EventBus) Request(ctx context.Context, topic string, args ...interface{}) ([]interface{}, error) {
+	received, err := bus.request(ctx, topic, args, true)
+	var errs []error
+	for _, r := range received {
+		if r.err == nil {
+			return r.values, nil
+		}
+		errs = append(errs, r.err)
+	}
+	return nil, errors.Join(append(errs, err)...)
+}
+
+// RequestAll is like Request but waits for every responder and returns the values
+// of the successful replies along with the errors of the failed ones.
+func (bus *EventBus) RequestAll(ctx context.Context, topic string, args ...interface{}) ([][]interface{}, error) {
+	received, err := bus.request(ctx, topic, args, false)
+	var values [][]interface{}
+	var errs []error
+	for _, r := range received {
+		if r.err != nil {
+			errs = append(errs, r.err)
+			continue
+		}
+		values = append(values, r.values)
+	}
+	return values, errors.Join(append(errs, err)...)
+}
+
+func (bus *EventBus) request(ctx context.Context, topic string, args []interface{}, first bool) ([]reply, error) {
+	ev := newEvent(ctx, topic, args)
+	ev.reply = newReplies(first)
+	err := bus.publish(ev)
+	received, waitErr := ev.reply.wait(ctx)
+	if len(received) == 0 && waitErr == nil {
+		if err != nil {
+			return nil, err
+		}
+		return nil, ErrNoResponders
+	}
+	return received, errors.Join(err, waitErr)
+}
+
+type reply struct {
+	values []interface{}
+	err    error
+}
+
+func newReply(fn reflect.Value, out []reflect.Value) reply {
+	var r reply
+	fnType := fn.Type()
+	for i, v := range out {
+		if i == len(out)-1 && fnType.Out(i) == errorType {
+			if !v.IsNil() {
+				r.err = v.Interface().(error)
+			}
+			continue
+		}
+		r.values = append(r.values, v.Interface())
+	}
+	return r
+}
+
+// replies collects the replies to a request. Every responder the request is
+// delivered to is expected once and sends its replies once.
+type replies struct {
+	first    bool
+	lock     sync.Mutex
+	pending  int
+	received []reply
+	notify   chan struct{}
+}
+
+func newReplies(first bool) *replies {
+	return &replies{
+		first:  first,
+		notify: make(chan struct{}, 1),
+	}
+}
+
+func (r *replies) expect() {
+	r.lock.Lock()
+	r.pending++
+	r.lock.Unlock()
+}
+
+func (r *replies) send(received ...reply) {
+	r.lock.Lock()
+	r.pending--
+	r.received = append(r.received, received...)
+	r.lock.Unlock()
+	select {
+	case r.notify <- struct{}{}:
+	default:
+	}
+}
+
+// wait returns once every responder replied, or the first successful reply
+// arrived when only the first one was requested.
+func (r *replies) wait(ctx context.Context) ([]reply, error) {
+	for {
+		r.lock.Lock()
+		done := r.pending == 0
+		for _, rep := range r.received {
+			if r.first && rep.err == nil {
+				done = true
+			}
+		}
+		received := make([]reply, len(r.received))
+		copy(received, r.received)
+		r.lock.Unlock()
+		if done {
+			return received, nil
+		}
+		select {
+		case <-r.notify:
+		case <-ctx.Done():
+			return received, ctx.Err()
+		}
+	}
+}
+
+// requestDistribution is implemented by internal distributions registered through
+// SubscribeWith that answer requests.
+type requestDistribution interface {
+	distRequest(ctx context.Context, topic string, args []interface{}, rep *replies) error
+}
@@ -0,0 +1,96 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestRequest(t *testing.T) {
+	e := New()
+	topic := "calculator"
+	e.Subscribe(topic, func(a, b int) int {
+		return a + b
+	})
+	e.SubscribeSync(topic, func(a, b int) {
+		t.Error("handlers without return values should not receive requests")
+	})
+	values, err := e.Request(context.Background(), topic, 10, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 1 || values[0] != 30 {
+		t.Fatalf("unexpected reply %v", values)
+	}
+}
+
+func TestRequestAll(t *testing.T) {
+	e := New()
+	topic := "calculator"
+	e.Subscribe(topic, func(a, b int) (int, error) {
+		return a + b, nil
+	})
+	e.SubscribeSync(topic, func(a, b int) (int, error) {
+		return a * b, nil
+	})
+	e.SubscribeOrdered(topic, func(a, b int) (int, error) {
+		return 0, errors.New("unsupported")
+	})
+	replies, err := e.RequestAll(context.Background(), topic, 10, 20)
+	if err == nil || err.Error() != "unsupported" {
+		t.Fatalf("expected the failed reply, got %v", err)
+	}
+	var results []int
+	for _, values := range replies {
+		results = append(results, values[0].(int))
+	}
+	sort.Ints(results)
+	if len(results) != 2 || results[0] != 30 || results[1] != 200 {
+		t.Fatalf("unexpected replies %v", results)
+	}
+}
+
+func TestRequestSkipsFailedReplies(t *testing.T) {
+	e := New(WithErrorHandler(nil))
+	topic := "calculator"
+	e.SubscribeSync(topic, func(a, b int) int {
+		panic("boom")
+	}, WithPriority(1))
+	e.SubscribeSync(topic, func(a, b int) int {
+		return a + b
+	})
+	values, err := e.Request(context.Background(), topic, 10, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values[0] != 30 {
+		t.Fatalf("unexpected reply %v", values)
+	}
+}
+
+func TestRequestNoResponders(t *testing.T) {
+	e := New()
+	topic := "calculator"
+	e.SubscribeSync(topic, func(a, b int) {})
+	if _, err := e.Request(context.Background(), topic, 10, 20); !errors.Is(err, ErrNoResponders) {
+		t.Fatalf("expected ErrNoResponders, got %v", err)
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	e := New()
+	topic := "calculator"
+	release := make(chan struct{})
+	defer close(release)
+	e.Subscribe(topic, func(a, b int) int {
+		<-release
+		return a + b
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := e.Request(ctx, topic, 10, 20); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package eventbus
+
+import (
+	"context"
+	"sync"
+)
+
+type BusRetainer interface {
+	PublishRetained(topic string, args ...interface{}) error
+	Retained(topic string) ([]interface{}, bool)
+	ClearRetained(topic string)
+}
+
+// retainStore keeps the last event published to the retained topics.
+type retainStore struct {
+	patterns []string
+	lock     sync.RWMutex
+	events   map[string][]interface{}
+}
+
+func newRetainStore(patterns []string) *retainStore {
+	return &retainStore{
+		patterns: patterns,
+		events:   make(map[string][]interface{}),
+	}
+}
+
+// retains reports whether every publish to topic is retained.
+func (r *retainStore) retains(topic string) bool {
+	for _, p := range r.patterns {
+		if matchTopic(p, topic) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *retainStore) set(topic string, args []interface{}) {
+	r.lock.Lock()
+	r.events[topic] = args
+	r.lock.Unlock()
+}
+
+func (r *retainStore) get(topic string) ([]interface{}, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	args, ok := r.events[topic]
+	return args, ok
+}
+
+func (r *retainStore) clear(topic string) {
+	r.lock.Lock()
+	delete(r.events, topic)
+	r.lock.Unlock()
+}
+
+// match returns the retained events of the topics matching pattern.
+func (r *retainStore) match(pattern string) map[string][]interface{} {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	events := make(map[string][]interface{})
+	for topic, args := range r.events {
+		if matchTopic(pattern, topic) {
+			events[topic] = args
+		}
+	}
+	return events
+}
+
+type retainedKey struct{}
+
+// withRetained marks the context of a retained event, so that an RPCProxy
+// forwarding it has the peer retain it as well.
+func withRetained(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retainedKey{}, true)
+}
+
+func isRetained(ctx context.Context) bool {
+	retained, _ := ctx.Value(retainedKey{}).(bool)
+	return retained
+}
+
+// PublishRetained publishes args to topic and retains them as its last event,
+// which is delivered to every subscriber of the topic subscribing later on.
+func (bus *EventBus) PublishRetained(topic string, args ...interface{}) error {
+	return bus.publish(newEvent(withRetained(context.Background()), topic, args))
+}
+
+// Retained returns the last retained event of topic.
+func (bus *EventBus) Retained(topic string) ([]interface{}, bool) {
+	return bus.retained.get(topic)
+}
+
+// ClearRetained removes the retained event of topic.
+func (bus *EventBus) ClearRetained(topic string) {
+	bus.retained.clear(topic)
+}
+
+// deliverRetained hands the retained events of the topics matching the pattern of
+// sub over to it. A publish racing with the subscribe may be delivered before them.
+func (bus *EventBus) deliverRetained(sub *subscription) {
+	for topic, args := range bus.retained.match(sub.topic) {
+		ev := newEvent(withRetained(context.Background()), topic, args)
+		if err := bus.sigs.check(sub.topic, args); err != nil {
+			continue
+		}
+		if !sub.accepts(ev) {
+			continue
+		}
+		sub.Dist(ev)
+		if err := ev.err(); err != nil {
+			bus.onError(err)
+		}
+	}
+}
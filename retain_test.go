@@ -0,0 +1,129 @@
+package eventbus
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestPublishRetained(t *testing.T) {
+	e := New()
+	topic := "config"
+	if err := e.PublishRetained(topic, "v1"); err != nil {
+		t.Fatal(err)
+	}
+	e.PublishRetained(topic, "v2")
+
+	var got []string
+	e.SubscribeSync(topic, func(v string) {
+		got = append(got, v)
+	})
+	ordered := make(chan string, 2)
+	e.SubscribeOrdered(topic, func(v string) {
+		ordered <- v
+	})
+	e.Publish(topic, "v3")
+
+	if !reflect.DeepEqual(got, []string{"v2", "v3"}) {
+		t.Fatalf("unexpected deliveries %v", got)
+	}
+	if v := <-ordered; v != "v2" {
+		t.Fatalf("expected the retained event first, got %s", v)
+	}
+	if args, ok := e.Retained(topic); !ok || args[0] != "v2" {
+		t.Fatalf("a plain publish should not replace the retained event, got %v", args)
+	}
+}
+
+func TestWithRetain(t *testing.T) {
+	e := New(WithRetain("config.#"))
+	e.Publish("config.db", "db1")
+	e.Publish("config.cache", "cache1")
+	e.Publish("orders", "o1")
+
+	var got []string
+	e.SubscribeSync("config.*", func(v string) {
+		got = append(got, v)
+	})
+	sort.Strings(got)
+	if !reflect.DeepEqual(got, []string{"cache1", "db1"}) {
+		t.Fatalf("unexpected deliveries %v", got)
+	}
+	if _, ok := e.Retained("orders"); ok {
+		t.Fatal("orders should not be retained")
+	}
+
+	e.ClearRetained("config.db")
+	if _, ok := e.Retained("config.db"); ok {
+		t.Fatal("retained event should be cleared")
+	}
+	got = nil
+	e.SubscribeSync("config.db", func(v string) {
+		got = append(got, v)
+	})
+	if len(got) != 0 {
+		t.Fatalf("cleared event was delivered: %v", got)
+	}
+}
+
+func TestRetainedOnceAndFilter(t *testing.T) {
+	e := New()
+	topic := "config"
+	e.PublishRetained(topic, "v1")
+	once, _ := e.SubscribeOnceSync(topic, func(v string) {})
+	if once.Active() {
+		t.Fatal("the retained event should consume a once subscription")
+	}
+	e.SubscribeSync(topic, func(v string) {
+		t.Error("filtered retained event was delivered")
+	}, WithFieldFilter(FieldFilter{Value: "v2"}))
+}
+
+func TestRetainedSignatureMismatch(t *testing.T) {
+	e := New(WithErrorHandler(nil))
+	topic := "config"
+	e.SubscribeSync(topic, func(v string) {})
+	if err := e.PublishRetained(topic, 1); err == nil {
+		t.Fatal("expected a signature mismatch")
+	}
+	if _, ok := e.Retained(topic); ok {
+		t.Fatal("a rejected event should not be retained")
+	}
+}
+
+func TestRPCProxyRetained(t *testing.T) {
+	pub, sub := newTestProxyPair(t)
+	if err := pub.PublishRetained("config", "v1"); err != nil {
+		t.Fatal(err)
+	}
+	got := make(chan string, 2)
+	for i := 0; i < 2; i++ {
+		if _, err := sub.Subscribe("config", func(v string) {
+			got <- v
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case v := <-got:
+			if v != "v1" {
+				t.Fatalf("unexpected delivery %s", v)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("retained event was not delivered to the remote subscriber")
+		}
+	}
+	if args, ok := sub.Retained("config"); !ok || args[0] != "v1" {
+		t.Fatalf("expected the peer to retain the event, got %v", args)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	select {
+	case v := <-got:
+		t.Fatalf("unexpected extra delivery %s", v)
+	case <-ctx.Done():
+	}
+}
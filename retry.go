@@ -0,0 +1,69 @@
+package eventbus
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy retries failed deliveries with an exponential backoff.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts including the first one, 3 when zero.
+	MaxAttempts int
+	// Backoff is the delay before the first retry, 100ms when zero. It is multiplied
+	// by Multiplier, 2 when zero, for every further retry up to MaxBackoff when set.
+	Backoff    time.Duration
+	Multiplier float64
+	MaxBackoff time.Duration
+	// Jitter randomly shortens every delay by up to this fraction of it, between 0 and 1.
+	Jitter float64
+	// Retryable reports whether a delivery failing with err is retried, every error is when nil.
+	Retryable func(err error) bool
+}
+
+func (p RetryPolicy) withDefaults() *RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = 3
+	}
+	if p.Backoff <= 0 {
+		p.Backoff = 100 * time.Millisecond
+	}
+	if p.Multiplier <= 0 {
+		p.Multiplier = 2
+	}
+	p.Jitter = math.Max(0, math.Min(1, p.Jitter))
+	return &p
+}
+
+// retries reports whether a delivery failing with err after attempts is retried.
+func (p *RetryPolicy) retries(attempts int, err error) bool {
+	if p == nil || attempts >= p.MaxAttempts {
+		return false
+	}
+	return p.Retryable == nil || p.Retryable(err)
+}
+
+func (p *RetryPolicy) backoff(attempts int) time.Duration {
+	d := float64(p.Backoff) * math.Pow(p.Multiplier, float64(attempts-1))
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	d -= d * p.Jitter * rand.Float64()
+	return time.Duration(d)
+}
+
+// wait sleeps until the retry following attempts, it reports false when ctx
+// is done or stop is closed first.
+func (p *RetryPolicy) wait(ctx context.Context, attempts int, stop <-chan struct{}) bool {
+	t := time.NewTimer(p.backoff(attempts))
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	case <-stop:
+		return false
+	}
+}
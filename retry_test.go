@@ -0,0 +1,144 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var errTransient = errors.New("transient")
+
+func TestRetry(t *testing.T) {
+	e := New(WithDeadLetter(), WithErrorHandler(nil))
+	topic := "orders.created"
+	var calls atomic.Int32
+	done := make(chan struct{})
+	e.Subscribe(topic, func(id string) error {
+		if calls.Add(1) < 3 {
+			return errTransient
+		}
+		close(done)
+		return nil
+	}, WithRetry(RetryPolicy{MaxAttempts: 5, Backoff: time.Millisecond}))
+	dead := &deadLetterRecorder{}
+	e.SubscribeSync(topic+DeadLetterSuffix, dead.record)
+	e.Publish(topic, "o-1")
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("handler was not retried")
+	}
+	e.Drain(context.Background())
+	if n := calls.Load(); n != 3 {
+		t.Fatalf("expected 3 attempts, got %d", n)
+	}
+	if got := dead.letters(); len(got) != 0 {
+		t.Fatalf("expected no dead letters, got %v", got)
+	}
+}
+
+func TestRetryExhausted(t *testing.T) {
+	e := New(WithDeadLetter(), WithErrorHandler(nil))
+	topic := "orders.created"
+	var calls atomic.Int32
+	done := make(chan struct{})
+	e.SubscribeOrdered(topic, func(id string) error {
+		if calls.Add(1) == 3 {
+			close(done)
+		}
+		return errTransient
+	}, WithRetry(RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond, Jitter: 0.5}))
+	dead := &deadLetterRecorder{}
+	e.SubscribeSync(topic+DeadLetterSuffix, dead.record)
+	e.Publish(topic, "o-1")
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("handler was not retried")
+	}
+	e.Drain(context.Background())
+	if n := calls.Load(); n != 3 {
+		t.Fatalf("expected 3 attempts, got %d", n)
+	}
+	got := dead.letters()
+	if len(got) != 1 || got[0].Attempts != 3 {
+		t.Fatalf("expected a dead letter after 3 attempts, got %v", got)
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	e := New(WithErrorHandler(nil))
+	var calls atomic.Int32
+	e.Subscribe("orders.created", func(id string) error {
+		calls.Add(1)
+		return errors.New("invalid order")
+	}, WithRetry(RetryPolicy{
+		Backoff: time.Millisecond,
+		Retryable: func(err error) bool {
+			return errors.Is(err, errTransient)
+		},
+	}))
+	e.Publish("orders.created", "o-1")
+	e.Drain(context.Background())
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("expected a single attempt, got %d", n)
+	}
+}
+
+func TestRetrySync(t *testing.T) {
+	e := New(WithErrorHandler(nil))
+	var calls atomic.Int32
+	e.SubscribeSync("orders.created", func(id string) error {
+		calls.Add(1)
+		return errTransient
+	}, WithRetry(RetryPolicy{Backoff: time.Millisecond}))
+	e.Publish("orders.created", "o-1")
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("expected synchronous subscribers not to be retried, got %d attempts", n)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	p := RetryPolicy{Backoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond}.withDefaults()
+	expected := []time.Duration{10, 20, 40, 50, 50}
+	for i, d := range expected {
+		if got := p.backoff(i + 1); got != d*time.Millisecond {
+			t.Fatalf("expected backoff %v after %d attempts, got %v", d*time.Millisecond, i+1, got)
+		}
+	}
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if got := p.backoff(1); got < 5*time.Millisecond || got > 10*time.Millisecond {
+			t.Fatalf("backoff %v out of the jitter range", got)
+		}
+	}
+}
+
+func TestRetryStopsOnClose(t *testing.T) {
+	e := New(WithDeadLetter(), WithErrorHandler(nil))
+	topic := "orders.created"
+	var calls atomic.Int32
+	e.Subscribe(topic, func(id string) error {
+		calls.Add(1)
+		return errTransient
+	}, WithRetry(RetryPolicy{MaxAttempts: 5, Backoff: 400 * time.Millisecond}))
+	dead := &deadLetterRecorder{}
+	e.SubscribeSync(topic+DeadLetterSuffix, dead.record)
+	e.Publish(topic, "o-1")
+	time.Sleep(50 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if err := e.Close(ctx); err != nil {
+		t.Fatalf("expected the backoff to end on Close, got %v", err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("expected 1 attempt, got %d", n)
+	}
+	got := dead.letters()
+	if len(got) != 1 || got[0].Attempts != 1 {
+		t.Fatalf("expected a dead letter after 1 attempt, got %v", got)
+	}
+}
@@ -0,0 +1,207 @@
+package eventbus
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// SchedulePolicy decides what happens to the scheduled events that are still
+// pending when the bus is drained or closed.
+type SchedulePolicy int
+
+const (
+	// ScheduleDiscard drops the pending events.
+	ScheduleDiscard SchedulePolicy = iota
+	// ScheduleFlush publishes the pending events right away.
+	ScheduleFlush
+)
+
+// Scheduled is an event waiting to be published by PublishAfter or PublishAt.
+type Scheduled interface {
+	Topic() string
+	At() time.Time
+	// Cancel prevents the event from being published, it reports false when
+	// the event was published or canceled already.
+	Cancel() bool
+}
+
+type scheduledEvent struct {
+	at        time.Time
+	topic     string
+	args      []interface{}
+	index     int
+	scheduler *scheduler
+}
+
+func (e *scheduledEvent) Topic() string {
+	return e.topic
+}
+
+func (e *scheduledEvent) At() time.Time {
+	return e.at
+}
+
+func (e *scheduledEvent) Cancel() bool {
+	return e.scheduler.cancel(e)
+}
+
+type scheduledQueue []*scheduledEvent
+
+func (q scheduledQueue) Len() int { return len(q) }
+
+func (q scheduledQueue) Less(i, j int) bool { return q[i].at.Before(q[j].at) }
+
+func (q scheduledQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *scheduledQueue) Push(x any) {
+	e := x.(*scheduledEvent)
+	e.index = len(*q)
+	*q = append(*q, e)
+}
+
+func (q *scheduledQueue) Pop() any {
+	old := *q
+	e := old[len(old)-1]
+	old[len(old)-1] = nil
+	e.index = -1
+	*q = old[:len(old)-1]
+	return e
+}
+
+// scheduler publishes the scheduled events of a bus with a single timer
+// armed for the earliest one.
+type scheduler struct {
+	publish func(e *scheduledEvent)
+
+	lock    sync.Mutex
+	queue   scheduledQueue
+	timer   *time.Timer
+	stopped bool
+	firing  sync.WaitGroup
+}
+
+func newScheduler(publish func(e *scheduledEvent)) *scheduler {
+	return &scheduler{
+		publish: publish,
+	}
+}
+
+func (s *scheduler) schedule(at time.Time, topic string, args []interface{}) (*scheduledEvent, error) {
+	e := &scheduledEvent{
+		at:        at,
+		topic:     topic,
+		args:      args,
+		scheduler: s,
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.stopped {
+		return nil, ErrClosed
+	}
+	heap.Push(&s.queue, e)
+	if e.index == 0 {
+		s.arm()
+	}
+	return e, nil
+}
+
+// arm sets the timer to the earliest event, the lock has to be held.
+func (s *scheduler) arm() {
+	if len(s.queue) == 0 {
+		return
+	}
+	d := time.Until(s.queue[0].at)
+	if s.timer == nil {
+		s.timer = time.AfterFunc(d, s.fire)
+		return
+	}
+	s.timer.Reset(d)
+}
+
+// fire publishes the events that are due and rearms the timer.
+func (s *scheduler) fire() {
+	s.lock.Lock()
+	if s.stopped {
+		s.lock.Unlock()
+		return
+	}
+	now := time.Now()
+	var due []*scheduledEvent
+	for len(s.queue) > 0 && !s.queue[0].at.After(now) {
+		due = append(due, heap.Pop(&s.queue).(*scheduledEvent))
+	}
+	s.arm()
+	s.firing.Add(1)
+	s.lock.Unlock()
+	defer s.firing.Done()
+	for _, e := range due {
+		s.publish(e)
+	}
+}
+
+func (s *scheduler) cancel(e *scheduledEvent) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if e.index < 0 {
+		return false
+	}
+	heap.Remove(&s.queue, e.index)
+	return true
+}
+
+// stop rejects new events and returns the pending ones in publish order
+// once the due events being published are done.
+func (s *scheduler) stop() []*scheduledEvent {
+	s.lock.Lock()
+	s.stopped = true
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	pending := make([]*scheduledEvent, 0, len(s.queue))
+	for len(s.queue) > 0 {
+		pending = append(pending, heap.Pop(&s.queue).(*scheduledEvent))
+	}
+	s.lock.Unlock()
+	s.firing.Wait()
+	return pending
+}
+
+// PublishAfter publishes args to topic once d has elapsed.
+// Errors of the delayed publish are passed to the error handler.
+func (bus *EventBus) PublishAfter(d time.Duration, topic string, args ...interface{}) (Scheduled, error) {
+	return bus.PublishAt(time.Now().Add(d), topic, args...)
+}
+
+// PublishAt publishes args to topic at t, or right away when t is in the past.
+func (bus *EventBus) PublishAt(t time.Time, topic string, args ...interface{}) (Scheduled, error) {
+	if err := validateTopic(topic); err != nil {
+		return nil, err
+	}
+	e, err := bus.scheduler.schedule(t, topic, args)
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (bus *EventBus) publishScheduled(e *scheduledEvent) {
+	if err := bus.PublishE(e.topic, e.args...); err != nil {
+		bus.onError(err)
+	}
+}
+
+// stopScheduler applies the schedule policy to the pending events.
+func (bus *EventBus) stopScheduler() {
+	pending := bus.scheduler.stop()
+	if bus.schedulePolicy != ScheduleFlush {
+		return
+	}
+	for _, e := range pending {
+		bus.publishScheduled(e)
+	}
+}
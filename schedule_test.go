@@ -0,0 +1,108 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPublishAfter(t *testing.T) {
+	e := New()
+	topic := "reminder"
+	var lock sync.Mutex
+	var got []int
+	done := make(chan struct{})
+	e.SubscribeSync(topic, func(i int) {
+		lock.Lock()
+		defer lock.Unlock()
+		got = append(got, i)
+		if len(got) == 3 {
+			close(done)
+		}
+	})
+	for _, i := range []int{30, 10, 20} {
+		if _, err := e.PublishAfter(time.Duration(i)*time.Millisecond, topic, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("scheduled events were not published")
+	}
+	if !reflect.DeepEqual(got, []int{10, 20, 30}) {
+		t.Fatalf("unexpected deliveries %v", got)
+	}
+}
+
+func TestPublishAtCancel(t *testing.T) {
+	e := New()
+	topic := "reminder"
+	got := make(chan int, 2)
+	e.SubscribeSync(topic, func(i int) {
+		got <- i
+	})
+	canceled, _ := e.PublishAfter(20*time.Millisecond, topic, 1)
+	past, _ := e.PublishAt(time.Now().Add(-time.Second), topic, 2)
+	if !canceled.Cancel() || canceled.Cancel() {
+		t.Fatal("expected a single successful Cancel")
+	}
+	select {
+	case i := <-got:
+		if i != 2 {
+			t.Fatalf("unexpected delivery %d", i)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("an event scheduled in the past should be published right away")
+	}
+	if past.Cancel() {
+		t.Fatal("a published event can not be canceled")
+	}
+	select {
+	case i := <-got:
+		t.Fatalf("canceled event was published: %d", i)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestPublishAfterInvalidTopic(t *testing.T) {
+	e := New()
+	if _, err := e.PublishAfter(time.Millisecond, "orders.*", 1); err == nil {
+		t.Fatal("expected wildcard topic to be rejected")
+	}
+}
+
+func TestScheduleDiscardOnDrain(t *testing.T) {
+	e := New()
+	topic := "reminder"
+	e.SubscribeSync(topic, func(i int) {
+		t.Error("discarded event was published")
+	})
+	e.PublishAfter(time.Hour, topic, 1)
+	if err := e.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.PublishAfter(time.Millisecond, topic, 2); !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+}
+
+func TestScheduleFlushOnDrain(t *testing.T) {
+	e := New(WithSchedulePolicy(ScheduleFlush))
+	topic := "reminder"
+	var got []int
+	e.SubscribeSync(topic, func(i int) {
+		got = append(got, i)
+	})
+	e.PublishAfter(2*time.Hour, topic, 2)
+	e.PublishAfter(time.Hour, topic, 1)
+	if err := e.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Fatalf("expected pending events to be flushed in order, got %v", got)
+	}
+}
@@ -0,0 +1,165 @@
+package eventbus
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// SignatureMismatchError reports handler parameters or published arguments
+// that do not match the signature of the topic.
+type SignatureMismatchError struct {
+	Topic    string
+	Expected []reflect.Type
+	Actual   []reflect.Type
+}
+
+func (e *SignatureMismatchError) Error() string {
+	return fmt.Sprintf("topic %q expects %s, got %s", e.Topic, typesString(e.Expected), typesString(e.Actual))
+}
+
+// signature describes the published arguments a handler accepts.
+// A leading context.Context parameter is not part of them, it receives the
+// context of the publisher.
+type signature struct {
+	in       []reflect.Type
+	variadic bool
+	context  bool
+}
+
+func newSignature(fnType reflect.Type) *signature {
+	start := 0
+	if fnType.NumIn() > 0 && fnType.In(0) == contextType {
+		start = 1
+	}
+	in := make([]reflect.Type, fnType.NumIn()-start)
+	for i := range in {
+		in[i] = fnType.In(i + start)
+	}
+	return &signature{
+		in:       in,
+		variadic: fnType.IsVariadic(),
+		context:  start == 1,
+	}
+}
+
+func (s *signature) equal(o *signature) bool {
+	if s.variadic != o.variadic || len(s.in) != len(o.in) {
+		return false
+	}
+	for i := range s.in {
+		if s.in[i] != o.in[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *signature) accept(args []interface{}) bool {
+	n := len(s.in)
+	if s.variadic {
+		if len(args) < n-1 {
+			return false
+		}
+	} else if len(args) != n {
+		return false
+	}
+	for i, v := range args {
+		if !assignable(v, s.paramType(i)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *signature) paramType(i int) reflect.Type {
+	if s.variadic && i >= len(s.in)-1 {
+		return s.in[len(s.in)-1].Elem()
+	}
+	return s.in[i]
+}
+
+func assignable(v interface{}, t reflect.Type) bool {
+	if v == nil {
+		switch t.Kind() {
+		case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Pointer, reflect.Slice, reflect.UnsafePointer:
+			return true
+		}
+		return false
+	}
+	return reflect.TypeOf(v).AssignableTo(t)
+}
+
+func argTypes(args []interface{}) []reflect.Type {
+	types := make([]reflect.Type, len(args))
+	for i, v := range args {
+		types[i] = reflect.TypeOf(v)
+	}
+	return types
+}
+
+func typesString(types []reflect.Type) string {
+	names := make([]string, len(types))
+	for i, t := range types {
+		if t == nil {
+			names[i] = "nil"
+			continue
+		}
+		names[i] = t.String()
+	}
+	return "(" + strings.Join(names, ", ") + ")"
+}
+
+// signatureRegistry remembers the handler signature of each topic.
+// The first handler subscribed to a topic fixes its signature until
+// every handler of the topic is unsubscribed.
+type signatureRegistry struct {
+	sync.RWMutex
+	signatures map[string]*signature
+}
+
+func newSignatureRegistry() *signatureRegistry {
+	return &signatureRegistry{
+		signatures: make(map[string]*signature),
+	}
+}
+
+func (r *signatureRegistry) bind(topic string, fnType reflect.Type) error {
+	sig := newSignature(fnType)
+	r.Lock()
+	defer r.Unlock()
+	expected, ok := r.signatures[topic]
+	if !ok {
+		r.signatures[topic] = sig
+		return nil
+	}
+	if !expected.equal(sig) {
+		return &SignatureMismatchError{
+			Topic:    topic,
+			Expected: expected.in,
+			Actual:   sig.in,
+		}
+	}
+	return nil
+}
+
+func (r *signatureRegistry) release(topic string) {
+	r.Lock()
+	delete(r.signatures, topic)
+	r.Unlock()
+}
+
+func (r *signatureRegistry) check(topic string, args []interface{}) error {
+	r.RLock()
+	expected, ok := r.signatures[topic]
+	r.RUnlock()
+	if !ok || expected.accept(args) {
+		return nil
+	}
+	return &SignatureMismatchError{
+		Topic:    topic,
+		Expected: expected.in,
+		Actual:   argTypes(args),
+	}
+}
@@ -0,0 +1,94 @@
+package eventbus
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSubscribeSignatureMismatch(t *testing.T) {
+	e := New()
+	topic := "testpub1"
+	if _, err := e.SubscribeSync(topic, func(name string) {}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.SubscribeSync(topic, func(name string) {}); err != nil {
+		t.Fatal(err)
+	}
+	_, err := e.Subscribe(topic, func(id int) {})
+	var mismatch *SignatureMismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("expected SignatureMismatchError, got %v", err)
+	}
+	if mismatch.Topic != topic || mismatch.Actual[0].Kind() != reflect.Int {
+		t.Fatalf("unexpected error content %v", mismatch)
+	}
+}
+
+func TestPublishESignatureMismatch(t *testing.T) {
+	e := New()
+	topic := "testpub1"
+	called := 0
+	e.SubscribeSync(topic, func(name string, age int) {
+		called++
+	})
+
+	tests := []struct {
+		name string
+		args []interface{}
+		ok   bool
+	}{
+		{"match", []interface{}{"jack", 18}, true},
+		{"fewer args", []interface{}{"jack"}, false},
+		{"more args", []interface{}{"jack", 18, 1}, false},
+		{"wrong type", []interface{}{"jack", "18"}, false},
+		{"nil for value type", []interface{}{"jack", nil}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := e.PublishE(topic, tt.args...)
+			if tt.ok && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			var mismatch *SignatureMismatchError
+			if !tt.ok && !errors.As(err, &mismatch) {
+				t.Fatalf("expected SignatureMismatchError, got %v", err)
+			}
+		})
+	}
+	if called != 1 {
+		t.Fatalf("expected handler to be called once, got %d", called)
+	}
+}
+
+func TestPublishMismatchDoesNotPanic(t *testing.T) {
+	e := New()
+	topic := "testpub1"
+	e.Subscribe(topic, func(name string) {
+		t.Error("handler should not be called")
+	})
+	e.Publish(topic)
+	e.Publish(topic, 1)
+}
+
+func TestPublishVariadic(t *testing.T) {
+	e := New()
+	topic := "testpub1"
+	var got []string
+	e.SubscribeSync(topic, func(prefix string, names ...string) {
+		got = append(got, prefix)
+		got = append(got, names...)
+	})
+	if err := e.PublishE(topic, "hi"); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.PublishE(topic, "hello", "jack", "lee"); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.PublishE(topic, "hello", 1); err == nil {
+		t.Fatal("expected mismatch error")
+	}
+	if len(got) != 4 {
+		t.Fatalf("unexpected args %v", got)
+	}
+}
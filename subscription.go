@@ -0,0 +1,221 @@
+package eventbus
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"github.com/danielhookx/fission"
+)
+
+// Subscription identifies a single subscribe call, independently of the
+// handler it was made with.
+type Subscription interface {
+	Topic() string
+	Unsubscribe() error
+	Active() bool
+}
+
+// subscriptionID keys handler subscriptions in their center, it can not collide
+// with the keys passed to SubscribeWith.
+type subscriptionID uint64
+
+// subscription is the distribution registered in the center of its topic.
+// It forwards events to the distribution doing the actual delivery.
+type subscription struct {
+	fission.Distribution
+	topic   string
+	key     any
+	handler uintptr
+	// signed is set when the handler bound the signature of the topic
+	signed bool
+	// responder is set when the subscription answers requests
+	responder bool
+	filter    func(args []interface{}) bool
+	gate      *replayGate
+	limiter   *rateLimiter
+	once      bool
+	priority  int
+	// inactive is called when the subscription is unsubscribed or its only delivery is done
+	inactive []func()
+	bus      *EventBus
+	active   atomic.Bool
+}
+
+func (s *subscription) Key() any {
+	return s.key
+}
+
+func (s *subscription) Topic() string {
+	return s.topic
+}
+
+func (s *subscription) Active() bool {
+	return s.active.Load()
+}
+
+func (s *subscription) Unsubscribe() error {
+	s.bus.unsubscribe(s)
+	return nil
+}
+
+func (s *subscription) Dist(data any) error {
+	ev := data.(*event)
+	if s.gate != nil && !s.gate.admit(ev) {
+		return nil
+	}
+	if s.limiter != nil {
+		s.limiter.offer(ev)
+		return nil
+	}
+	return s.dist(ev)
+}
+
+func (s *subscription) deactivated() {
+	for _, f := range s.inactive {
+		f()
+	}
+}
+
+func (s *subscription) dist(data any) error {
+	if s.once {
+		// only the publish deactivating the subscription delivers to it
+		if !s.active.CompareAndSwap(true, false) {
+			return nil
+		}
+		s.bus.detach(s)
+		s.deactivated()
+		return s.Distribution.Dist(data)
+	}
+	if !s.active.Load() {
+		return nil
+	}
+	return s.Distribution.Dist(data)
+}
+
+// subscriptionRegistry tracks the subscriptions of every topic pattern and
+// keeps the topic signatures in step with the handlers subscribed to it.
+type subscriptionRegistry struct {
+	sync.RWMutex
+	subs map[string][]*subscription
+	sigs *signatureRegistry
+}
+
+func newSubscriptionRegistry(sigs *signatureRegistry) *subscriptionRegistry {
+	return &subscriptionRegistry{
+		subs: make(map[string][]*subscription),
+		sigs: sigs,
+	}
+}
+
+// add registers s, binding fnType as the signature of its topic when s has a handler.
+func (r *subscriptionRegistry) add(s *subscription, fnType reflect.Type) error {
+	r.Lock()
+	defer r.Unlock()
+	if fnType != nil {
+		if err := r.sigs.bind(s.topic, fnType); err != nil {
+			return err
+		}
+		s.signed = true
+	}
+	r.subs[s.topic] = append(r.subs[s.topic], s)
+	return nil
+}
+
+// remove unregisters s and releases the topic signature once no handler binding it is left.
+func (r *subscriptionRegistry) remove(s *subscription) {
+	r.Lock()
+	defer r.Unlock()
+	var subs []*subscription
+	handlers := 0
+	for _, sub := range r.subs[s.topic] {
+		if sub == s {
+			continue
+		}
+		subs = append(subs, sub)
+		if sub.signed {
+			handlers++
+		}
+	}
+	if handlers == 0 {
+		r.sigs.release(s.topic)
+	}
+	if len(subs) == 0 {
+		delete(r.subs, s.topic)
+		return
+	}
+	r.subs[s.topic] = subs
+}
+
+func (r *subscriptionRegistry) list(topic string) []*subscription {
+	r.RLock()
+	defer r.RUnlock()
+	subs := make([]*subscription, len(r.subs[topic]))
+	copy(subs, r.subs[topic])
+	return subs
+}
+
+func (r *subscriptionRegistry) topics() []string {
+	r.RLock()
+	defer r.RUnlock()
+	topics := make([]string, 0, len(r.subs))
+	for topic := range r.subs {
+		topics = append(topics, topic)
+	}
+	return topics
+}
+
+func (r *subscriptionRegistry) all() []*subscription {
+	r.RLock()
+	defer r.RUnlock()
+	var subs []*subscription
+	for _, topicSubs := range r.subs {
+		subs = append(subs, topicSubs...)
+	}
+	return subs
+}
+
+func (r *subscriptionRegistry) find(topic string, match func(s *subscription) bool) []*subscription {
+	var subs []*subscription
+	for _, s := range r.list(topic) {
+		if match(s) {
+			subs = append(subs, s)
+		}
+	}
+	return subs
+}
+
+// waitDistribution hands the arguments of an event over to WaitFor.
+type waitDistribution struct {
+	ch     chan []interface{}
+	closed chan struct{}
+}
+
+func newWaitDistribution() *waitDistribution {
+	return &waitDistribution{
+		ch:     make(chan []interface{}, 1),
+		closed: make(chan struct{}),
+	}
+}
+
+func (d *waitDistribution) Register(ctx context.Context) {
+	return
+}
+
+func (d *waitDistribution) Key() any {
+	return d.ch
+}
+
+func (d *waitDistribution) Dist(data any) error {
+	select {
+	case d.ch <- data.(*event).args:
+	default:
+	}
+	return nil
+}
+
+func (d *waitDistribution) Close() error {
+	close(d.closed)
+	return nil
+}
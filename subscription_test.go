@@ -0,0 +1,179 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSubscriptionIdentity(t *testing.T) {
+	e := New()
+	topic := "testpub1"
+	counts := make([]int, 3)
+	subs := make([]Subscription, 3)
+	for i := range counts {
+		i := i
+		sub, err := e.SubscribeSync(topic, func(name string) {
+			counts[i]++
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		subs[i] = sub
+	}
+	e.Publish(topic, "jack")
+	if err := subs[1].Unsubscribe(); err != nil {
+		t.Fatal(err)
+	}
+	e.Publish(topic, "jack2")
+	if counts[0] != 2 || counts[1] != 1 || counts[2] != 2 {
+		t.Fatalf("unexpected deliveries %v", counts)
+	}
+	if subs[1].Active() || !subs[0].Active() {
+		t.Fatal("unexpected active state")
+	}
+	if subs[0].Topic() != topic {
+		t.Fatalf("unexpected topic %s", subs[0].Topic())
+	}
+	if err := subs[1].Unsubscribe(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSubscriptionSameHandlerTwice(t *testing.T) {
+	e := New()
+	topic := "testpub1"
+	count := 0
+	fn := func(name string) {
+		count++
+	}
+	sub1, _ := e.SubscribeSync(topic, fn)
+	e.SubscribeSync(topic, fn)
+	e.Publish(topic, "jack")
+	sub1.Unsubscribe()
+	e.Publish(topic, "jack")
+	if count != 3 {
+		t.Fatalf("expected 3 deliveries, got %d", count)
+	}
+	e.Unsubscribe(topic, fn)
+	e.Publish(topic, "jack")
+	if count != 3 {
+		t.Fatalf("expected handler to be unsubscribed, got %d", count)
+	}
+}
+
+func TestSubscriptionAcrossTopics(t *testing.T) {
+	e := New()
+	count := 0
+	fn := func(name string) {
+		count++
+	}
+	e.SubscribeSync("testpub1", fn)
+	e.SubscribeSync("testpub2", fn)
+	e.Publish("testpub1", "jack")
+	if count != 1 {
+		t.Fatalf("expected 1 delivery, got %d", count)
+	}
+}
+
+func TestSubscriptionReleasesSignature(t *testing.T) {
+	e := New()
+	topic := "testpub1"
+	sub, _ := e.SubscribeSync(topic, func(name string) {})
+	if _, err := e.SubscribeSync(topic, func(id int) {}); err == nil {
+		t.Fatal("expected signature mismatch")
+	}
+	sub.Unsubscribe()
+	if _, err := e.SubscribeSync(topic, func(id int) {}); err != nil {
+		t.Fatalf("expected signature to be released, got %v", err)
+	}
+}
+
+func TestSubscribeWithHandle(t *testing.T) {
+	e := New()
+	topic := "testpub1"
+	sub1, _ := e.SubscribeWith(topic, "key1", createMockDistHandlerFunc)
+	sub2, _ := e.SubscribeWith(topic, "key1", createMockDistHandlerFunc)
+	if sub1 != sub2 {
+		t.Fatal("expected the same subscription for the same key")
+	}
+	e.Unsubscribe(topic, "key1")
+	if sub1.Active() {
+		t.Fatal("expected subscription to be removed by key")
+	}
+}
+
+func TestSubscribeOnceSync(t *testing.T) {
+	e := New()
+	topic := "testpub1"
+	count := 0
+	sub, _ := e.SubscribeOnceSync(topic, func(name string) {
+		count++
+	})
+	e.Publish(topic, "jack")
+	e.Publish(topic, "jack2")
+	if count != 1 {
+		t.Fatalf("expected 1 delivery, got %d", count)
+	}
+	if sub.Active() {
+		t.Fatal("expected subscription to be inactive after delivery")
+	}
+}
+
+func TestSubscribeOnceConcurrent(t *testing.T) {
+	e := New()
+	topic := "testpub1"
+	var count atomic.Int64
+	delivered := make(chan struct{}, 1)
+	e.SubscribeOnce(topic, func(i int) {
+		count.Add(1)
+		delivered <- struct{}{}
+	})
+	wg := sync.WaitGroup{}
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			e.Publish(topic, i)
+		}(i)
+	}
+	wg.Wait()
+	<-delivered
+	time.Sleep(time.Millisecond * 20)
+	if count.Load() != 1 {
+		t.Fatalf("expected 1 delivery, got %d", count.Load())
+	}
+}
+
+func TestWaitFor(t *testing.T) {
+	e := New()
+	topic := "orders.created"
+	go func() {
+		time.Sleep(time.Millisecond * 10)
+		e.Publish(topic, "jack", 18)
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	args, err := e.WaitFor(ctx, "orders.*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(args) != 2 || args[0] != "jack" || args[1] != 18 {
+		t.Fatalf("unexpected args %v", args)
+	}
+}
+
+func TestWaitForTimeout(t *testing.T) {
+	e := New()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	if _, err := e.WaitFor(ctx, "testpub1"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if subs := e.(*EventBus).subs.list("testpub1"); len(subs) != 0 {
+		t.Fatalf("expected waiter to be removed, got %d subscriptions", len(subs))
+	}
+}
@@ -0,0 +1,71 @@
+package eventbus
+
+import (
+	"context"
+)
+
+// Tracer traces a publish and the deliveries it results in, set with WithTracer.
+// The trace context is carried to the peers of an RPCProxy, so that their
+// deliveries are part of the same trace.
+type Tracer interface {
+	// StartPublish starts the span of a publish to topic as a child of the span in ctx, if any.
+	StartPublish(ctx context.Context, topic string) (context.Context, Span)
+	// StartDelivery starts the span of a delivery attempt as a child of the publish span in ctx.
+	StartDelivery(ctx context.Context, d *Delivery) (context.Context, Span)
+	// Inject writes the trace context of ctx to carrier.
+	Inject(ctx context.Context, carrier Metadata)
+	// Extract returns ctx with the trace context read from carrier.
+	Extract(ctx context.Context, carrier Metadata) context.Context
+}
+
+// Span is started by a Tracer and ended with the error the publish or delivery failed with.
+type Span interface {
+	End(err error)
+}
+
+// noopTracer is the default Tracer.
+type noopTracer struct{}
+
+func (noopTracer) StartPublish(ctx context.Context, topic string) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+func (noopTracer) StartDelivery(ctx context.Context, d *Delivery) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+func (noopTracer) Inject(ctx context.Context, carrier Metadata) {}
+
+func (noopTracer) Extract(ctx context.Context, carrier Metadata) context.Context {
+	return ctx
+}
+
+type noopSpan struct{}
+
+func (noopSpan) End(err error) {}
+
+// tracerProvider is implemented by the buses handing their tracer over to an RPCProxy.
+type tracerProvider interface {
+	busTracer() Tracer
+}
+
+func (bus *EventBus) busTracer() Tracer {
+	return bus.tracer
+}
+
+func tracerOf(bus Eventbus) Tracer {
+	if tp, ok := bus.(tracerProvider); ok {
+		return tp.busTracer()
+	}
+	return noopTracer{}
+}
+
+// injectTrace returns the trace context of ctx to be sent to a peer, nil when there is none.
+func injectTrace(tracer Tracer, ctx context.Context) Metadata {
+	carrier := Metadata{}
+	tracer.Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return nil
+	}
+	return carrier
+}
@@ -0,0 +1,162 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordedSpan struct {
+	id     int
+	parent int
+	name   string
+	err    error
+	ended  bool
+}
+
+type spanKey struct{}
+
+// recordingTracer records the spans it starts, their parent is the span in the context.
+type recordingTracer struct {
+	lock  sync.Mutex
+	spans []*recordedSpan
+}
+
+func (t *recordingTracer) start(ctx context.Context, name string) (context.Context, Span) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	parent, _ := ctx.Value(spanKey{}).(int)
+	s := &recordedSpan{id: len(t.spans) + 1, parent: parent, name: name}
+	t.spans = append(t.spans, s)
+	return context.WithValue(ctx, spanKey{}, s.id), &recordingSpan{tracer: t, span: s}
+}
+
+func (t *recordingTracer) StartPublish(ctx context.Context, topic string) (context.Context, Span) {
+	return t.start(ctx, "publish "+topic)
+}
+
+func (t *recordingTracer) StartDelivery(ctx context.Context, d *Delivery) (context.Context, Span) {
+	return t.start(ctx, "deliver "+d.Topic+" to "+d.Pattern)
+}
+
+func (t *recordingTracer) Inject(ctx context.Context, carrier Metadata) {
+	if id, ok := ctx.Value(spanKey{}).(int); ok {
+		carrier["span"] = strconv.Itoa(id)
+	}
+}
+
+func (t *recordingTracer) Extract(ctx context.Context, carrier Metadata) context.Context {
+	if id, err := strconv.Atoi(carrier["span"]); err == nil {
+		return context.WithValue(ctx, spanKey{}, id)
+	}
+	return ctx
+}
+
+func (t *recordingTracer) tree() []string {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	var tree []string
+	for _, s := range t.spans {
+		line := fmt.Sprintf("%d<-%d %s", s.id, s.parent, s.name)
+		if s.err != nil {
+			line += ": " + s.err.Error()
+		}
+		if !s.ended {
+			line += " (open)"
+		}
+		tree = append(tree, line)
+	}
+	return tree
+}
+
+type recordingSpan struct {
+	tracer *recordingTracer
+	span   *recordedSpan
+}
+
+func (s *recordingSpan) End(err error) {
+	s.tracer.lock.Lock()
+	s.span.err = err
+	s.span.ended = true
+	s.tracer.lock.Unlock()
+}
+
+func expectSpans(t *testing.T, tracer *recordingTracer, expected ...string) {
+	t.Helper()
+	got := tracer.tree()
+	if fmt.Sprint(got) != fmt.Sprint(expected) {
+		t.Fatalf("expected spans\n%v\ngot\n%v", expected, got)
+	}
+}
+
+func TestTracer(t *testing.T) {
+	tracer := &recordingTracer{}
+	e := New(WithTracer(tracer), WithErrorHandler(nil))
+	e.SubscribeSync("orders.*", func(ctx context.Context, id string) error {
+		// a publish of a handler is part of the trace
+		e.PublishContext(ctx, "audit", id)
+		return errors.New("fail")
+	})
+	e.SubscribeSync("audit", func(id string) {})
+	e.Publish("orders.created", "o-1")
+	expectSpans(t, tracer,
+		"1<-0 publish orders.created",
+		"2<-1 deliver orders.created to orders.*: fail",
+		"3<-2 publish audit",
+		"4<-3 deliver audit to audit",
+	)
+}
+
+func TestTracerAsync(t *testing.T) {
+	tracer := &recordingTracer{}
+	e := New(WithTracer(tracer))
+	e.Subscribe("orders.created", func(id string) {})
+	e.Publish("orders.created", "o-1")
+	e.Drain(context.Background())
+	expectSpans(t, tracer,
+		"1<-0 publish orders.created",
+		"2<-1 deliver orders.created to orders.created",
+	)
+}
+
+func TestRPCProxyTracer(t *testing.T) {
+	dir := t.TempDir()
+	a := "unix://" + dir + "/a.sock"
+	b := "unix://" + dir + "/b.sock"
+	pubTracer, subTracer := &recordingTracer{}, &recordingTracer{}
+	pub, err := NewRPCProxy(a, b, New(WithTracer(pubTracer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub, err := NewRPCProxy(b, a, New(WithTracer(subTracer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		pub.Close(context.Background())
+		sub.Close(context.Background())
+	})
+	done := make(chan struct{})
+	sub.SubscribeSync("orders.created", func(id string) {
+		close(done)
+	})
+	pub.Publish("orders.created", "o-1")
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("remote subscriber was not called")
+	}
+	expectSpans(t, pubTracer,
+		"1<-0 publish orders.created",
+		"2<-1 deliver orders.created to orders.created",
+	)
+	// the remote publish is a child of the delivery forwarding it
+	expectSpans(t, subTracer,
+		"1<-2 publish orders.created",
+		"2<-1 deliver orders.created to orders.created",
+	)
+}
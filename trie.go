@@ -0,0 +1,133 @@
+package eventbus
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+const (
+	topicSeparator = "."
+	// SingleLevelWildcard matches exactly one topic level, "orders.*" matches "orders.created".
+	SingleLevelWildcard = "*"
+	// MultiLevelWildcard matches zero or more trailing topic levels, "orders.#" matches
+	// "orders", "orders.created" and "orders.refund.issued".
+	MultiLevelWildcard = "#"
+)
+
+func validatePattern(pattern string) error {
+	segments := strings.Split(pattern, topicSeparator)
+	for i, seg := range segments {
+		if seg == MultiLevelWildcard && i != len(segments)-1 {
+			return fmt.Errorf("topic %q: %s must be the last level", pattern, MultiLevelWildcard)
+		}
+	}
+	return nil
+}
+
+func validateTopic(topic string) error {
+	for _, seg := range strings.Split(topic, topicSeparator) {
+		if seg == SingleLevelWildcard || seg == MultiLevelWildcard {
+			return fmt.Errorf("topic %q: wildcards can not be published to", topic)
+		}
+	}
+	return nil
+}
+
+// matchTopic reports whether topic matches pattern.
+func matchTopic(pattern, topic string) bool {
+	patterns := strings.Split(pattern, topicSeparator)
+	segments := strings.Split(topic, topicSeparator)
+	for i, p := range patterns {
+		if p == MultiLevelWildcard {
+			return true
+		}
+		if i >= len(segments) || (p != SingleLevelWildcard && p != segments[i]) {
+			return false
+		}
+	}
+	return len(patterns) == len(segments)
+}
+
+type topicNode struct {
+	pattern  string
+	center   *center
+	children map[string]*topicNode
+}
+
+// topicTree stores the center of every subscribed topic pattern by level,
+// so a published topic is matched against wildcard patterns without
+// scanning all of them.
+type topicTree struct {
+	sync.RWMutex
+	root *topicNode
+}
+
+func newTopicTree() *topicTree {
+	return &topicTree{
+		root: &topicNode{},
+	}
+}
+
+func (t *topicTree) put(pattern string) *center {
+	t.Lock()
+	defer t.Unlock()
+	node := t.root
+	for _, seg := range strings.Split(pattern, topicSeparator) {
+		child, ok := node.children[seg]
+		if !ok {
+			if node.children == nil {
+				node.children = make(map[string]*topicNode)
+			}
+			child = &topicNode{}
+			node.children[seg] = child
+		}
+		node = child
+	}
+	if node.center == nil {
+		node.pattern = pattern
+		node.center = newCenter()
+	}
+	return node.center
+}
+
+func (t *topicTree) get(pattern string) *center {
+	t.RLock()
+	defer t.RUnlock()
+	node := t.root
+	for _, seg := range strings.Split(pattern, topicSeparator) {
+		child, ok := node.children[seg]
+		if !ok {
+			return nil
+		}
+		node = child
+	}
+	return node.center
+}
+
+// match returns the nodes of all patterns matching topic.
+func (t *topicTree) match(topic string) []*topicNode {
+	t.RLock()
+	defer t.RUnlock()
+	var nodes []*topicNode
+	t.root.match(strings.Split(topic, topicSeparator), &nodes)
+	return nodes
+}
+
+func (n *topicNode) match(segments []string, nodes *[]*topicNode) {
+	if multi, ok := n.children[MultiLevelWildcard]; ok && multi.center != nil {
+		*nodes = append(*nodes, multi)
+	}
+	if len(segments) == 0 {
+		if n.center != nil {
+			*nodes = append(*nodes, n)
+		}
+		return
+	}
+	if child, ok := n.children[segments[0]]; ok {
+		child.match(segments[1:], nodes)
+	}
+	if single, ok := n.children[SingleLevelWildcard]; ok {
+		single.match(segments[1:], nodes)
+	}
+}
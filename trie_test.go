@@ -0,0 +1,137 @@
+package eventbus
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestTopicTreeMatch(t *testing.T) {
+	tree := newTopicTree()
+	patterns := []string{
+		"orders.created",
+		"orders.*",
+		"orders.#",
+		"orders.*.issued",
+		"#",
+		"*",
+		"users.created",
+	}
+	for _, p := range patterns {
+		tree.put(p)
+	}
+
+	tests := []struct {
+		topic    string
+		expected []string
+	}{
+		{"orders", []string{"#", "*", "orders.#"}},
+		{"orders.created", []string{"#", "orders.#", "orders.*", "orders.created"}},
+		{"orders.cancelled", []string{"#", "orders.#", "orders.*"}},
+		{"orders.refund.issued", []string{"#", "orders.#", "orders.*.issued"}},
+		{"users.deleted", []string{"#"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.topic, func(t *testing.T) {
+			var got []string
+			for _, node := range tree.match(tt.topic) {
+				got = append(got, node.pattern)
+			}
+			sort.Strings(got)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Fatalf("expected %v, got %v", tt.expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestMatchTopic(t *testing.T) {
+	tests := []struct {
+		pattern string
+		topic   string
+		match   bool
+	}{
+		{"orders.created", "orders.created", true},
+		{"orders.created", "orders.cancelled", false},
+		{"orders.*", "orders.created", true},
+		{"orders.*", "orders", false},
+		{"orders.*", "orders.refund.issued", false},
+		{"orders.#", "orders", true},
+		{"orders.#", "orders.refund.issued", true},
+		{"orders.*.issued", "orders.refund.issued", true},
+		{"#", "users", true},
+	}
+	for _, tt := range tests {
+		if got := matchTopic(tt.pattern, tt.topic); got != tt.match {
+			t.Errorf("matchTopic(%q, %q) = %v, expected %v", tt.pattern, tt.topic, got, tt.match)
+		}
+	}
+}
+
+func TestWildcardSubscribe(t *testing.T) {
+	e := New()
+	var single, multi, exact []string
+	e.SubscribeSync("orders.*", func(id string) {
+		single = append(single, id)
+	})
+	e.SubscribeSync("orders.#", func(id string) {
+		multi = append(multi, id)
+	})
+	e.SubscribeSync("orders.created", func(id string) {
+		exact = append(exact, id)
+	})
+	e.Publish("orders.created", "1")
+	e.Publish("orders.cancelled", "2")
+	e.Publish("orders.refund.issued", "3")
+	e.Publish("users.created", "4")
+	if len(single) != 2 || len(multi) != 3 || len(exact) != 1 {
+		t.Fatalf("unexpected deliveries single=%v multi=%v exact=%v", single, multi, exact)
+	}
+}
+
+func TestWildcardValidation(t *testing.T) {
+	e := New()
+	if _, err := e.SubscribeSync("orders.#.created", func(id string) {}); err == nil {
+		t.Fatal("expected error for # before the last level")
+	}
+	if _, err := e.SubscribeWith("orders.#.created", "key1", createMockDistHandlerFunc); err == nil {
+		t.Fatal("expected error for # before the last level")
+	}
+	if err := e.PublishE("orders.*", "1"); err == nil {
+		t.Fatal("expected error when publishing to a wildcard")
+	}
+}
+
+func TestWildcardSignatureMismatch(t *testing.T) {
+	e := New(WithErrorHandler(nil))
+	called := 0
+	e.SubscribeSync("orders.*", func(id int) {
+		called++
+	})
+	e.SubscribeSync("orders.created", func(id string) {
+		called++
+	})
+	if err := e.PublishE("orders.created", "1"); err == nil {
+		t.Fatal("expected mismatch error for orders.*")
+	}
+	if called != 1 {
+		t.Fatalf("expected matching subscriber to be called, got %d", called)
+	}
+}
+
+func BenchmarkTopicTreeMatch(b *testing.B) {
+	tree := newTopicTree()
+	tree.put("orders.*")
+	tree.put("orders.#")
+	for i := 0; i < 1000; i++ {
+		tree.put("topic.level." + string(rune('a'+i%26)) + ".x")
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree.match("orders.refund.issued")
+	}
+}
@@ -0,0 +1,35 @@
+package eventbus
+
+// Topic is a type-safe view of a topic whose events carry a single payload of type T.
+// It works on top of any Eventbus, so proxied buses get the same compile-time checks.
+type Topic[T any] struct {
+	bus  Eventbus
+	name string
+}
+
+func NewTopic[T any](bus Eventbus, name string) *Topic[T] {
+	return &Topic[T]{
+		bus:  bus,
+		name: name,
+	}
+}
+
+func (t *Topic[T]) Name() string {
+	return t.name
+}
+
+func (t *Topic[T]) Subscribe(fn func(T), opt ...SubscribeOption) (Subscription, error) {
+	return t.bus.Subscribe(t.name, fn, opt...)
+}
+
+func (t *Topic[T]) SubscribeSync(fn func(T), opt ...SubscribeOption) (Subscription, error) {
+	return t.bus.SubscribeSync(t.name, fn, opt...)
+}
+
+func (t *Topic[T]) Unsubscribe(fn func(T)) error {
+	return t.bus.Unsubscribe(t.name, fn)
+}
+
+func (t *Topic[T]) Publish(v T) {
+	t.bus.Publish(t.name, v)
+}
@@ -0,0 +1,72 @@
+package eventbus
+
+import (
+	"sync"
+	"testing"
+)
+
+type orderCreated struct {
+	ID    int
+	Owner string
+}
+
+func TestTopicSubscribeSync(t *testing.T) {
+	e := New()
+	topic := NewTopic[orderCreated](e, "orders.created")
+	var got []orderCreated
+	topic.SubscribeSync(func(v orderCreated) {
+		got = append(got, v)
+	})
+	topic.Publish(orderCreated{ID: 1, Owner: "jack"})
+	topic.Publish(orderCreated{ID: 2, Owner: "lee"})
+	if len(got) != 2 || got[0].ID != 1 || got[1].Owner != "lee" {
+		t.Fatalf("unexpected events %v", got)
+	}
+}
+
+func TestTopicSubscribe(t *testing.T) {
+	e := New()
+	topic := NewTopic[string](e, "testpub1")
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	topic.Subscribe(func(name string) {
+		defer wg.Done()
+		if name != "jack" {
+			t.Errorf("expected jack, got %s", name)
+		}
+	})
+	topic.Publish("jack")
+	wg.Wait()
+}
+
+func TestTopicNilPayload(t *testing.T) {
+	e := New()
+	topic := NewTopic[*testA](e, "testpub1")
+	called := false
+	topic.SubscribeSync(func(v *testA) {
+		called = true
+		if v != nil {
+			t.Errorf("expected nil payload, got %v", v)
+		}
+	})
+	topic.Publish(nil)
+	if !called {
+		t.Fatal("handler not called")
+	}
+}
+
+func TestTopicUnsubscribe(t *testing.T) {
+	e := New()
+	topic := NewTopic[int](e, "testpub1")
+	count := 0
+	fn := func(v int) {
+		count += v
+	}
+	topic.SubscribeSync(fn)
+	topic.Publish(1)
+	topic.Unsubscribe(fn)
+	topic.Publish(2)
+	if count != 1 {
+		t.Fatalf("expected 1, got %d", count)
+	}
+}